@@ -0,0 +1,926 @@
+// Code generated by go run ./cmd/openapi client. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Alert struct {
+	// Baseline mean normalized rating, or the previous overall score normalized to 0-100
+	Baseline   float64   `json:"baseline"`
+	DetectedAt time.Time `json:"detected_at"`
+	HotelID    int64     `json:"hotel_id"`
+	ID         int64     `json:"id"`
+	Kind       string    `json:"kind"`
+	Message    string    `json:"message"`
+	// Recent mean normalized rating, or the current overall score normalized to 0-100
+	Observed float64 `json:"observed"`
+	// Provider of an overall score drop, null for rating drops
+	ProviderID *int       `json:"provider_id"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	// z-score of the recent mean, or the size of the score drop
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity"`
+	// When the detector last confirmed the alert
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AlertList struct {
+	Alerts   []Alert  `json:"alerts"`
+	Metadata Metadata `json:"metadata"`
+}
+
+type AspectListResponse struct {
+	Aspects []AspectSummary `json:"aspects"`
+}
+
+type AspectSummary struct {
+	// Aspect name from the dictionary, e.g. cleanliness
+	Aspect string `json:"aspect"`
+	// Reviews mentioning the aspect in their negatives
+	Negative int `json:"negative"`
+	// (positive - negative) / (positive + negative)
+	NetScore float64 `json:"net_score"`
+	// Reviews mentioning the aspect in their positives
+	Positive int `json:"positive"`
+}
+
+type Breakdown struct {
+	Count int    `json:"count"`
+	Key   string `json:"key"`
+	// Mean normalized rating, 0-100
+	MeanRating float64 `json:"mean_rating"`
+}
+
+type CategoryComparison struct {
+	Category          string   `json:"category"`
+	DeltaFromPlatform *float64 `json:"delta_from_platform,omitempty"`
+	Disagreement      bool     `json:"disagreement"`
+	Max               *float64 `json:"max"`
+	Min               *float64 `json:"min"`
+	PlatformAverage   *float64 `json:"platform_average,omitempty"`
+	// Grades on a 0-100 scale keyed by provider name
+	Scores map[string]*float64 `json:"scores"`
+	Spread *float64            `json:"spread"`
+}
+
+type DateWindow struct {
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+type DuplicationReport struct {
+	Providers []ProviderDuplication `json:"providers"`
+}
+
+type GradeComparison struct {
+	Categories []CategoryComparison `json:"categories"`
+	HotelID    int64                `json:"hotel_id"`
+	Platform   string               `json:"platform"`
+	Providers  []ProviderGrades     `json:"providers"`
+	Threshold  float64              `json:"threshold"`
+}
+
+type GradeComparisonResponse struct {
+	Comparison GradeComparison `json:"comparison"`
+}
+
+type Healthcheck struct {
+	Status     string                `json:"status"`
+	SystemInfo HealthcheckSystemInfo `json:"system_info"`
+}
+
+type HealthcheckSystemInfo struct {
+	Environment string `json:"environment"`
+	Version     string `json:"version"`
+}
+
+type HistogramBucket struct {
+	Count int `json:"count"`
+	// Normalized ratings from rating up to, not including, rating+10
+	Rating int `json:"rating"`
+}
+
+type Hotel struct {
+	CreatedAt time.Time `json:"created_at"`
+	HotelID   int64     `json:"hotel_id"`
+	Name      string    `json:"name"`
+	Platform  string    `json:"platform"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type HotelDetail struct {
+	Hotel           Hotel                 `json:"hotel"`
+	ProviderRatings []HotelProviderRating `json:"provider_ratings"`
+	ReviewSummary   ReviewSummary         `json:"review_summary"`
+}
+
+type HotelList struct {
+	Hotels   []Hotel  `json:"hotels"`
+	Metadata Metadata `json:"metadata"`
+}
+
+type HotelProviderRating struct {
+	Cleanliness        *float64  `json:"cleanliness"`
+	CreatedAt          time.Time `json:"created_at"`
+	Facilities         *float64  `json:"facilities"`
+	HotelID            int64     `json:"hotel_id"`
+	Location           *float64  `json:"location"`
+	OverallScore       float64   `json:"overall_score"`
+	ProviderID         int       `json:"provider_id"`
+	ProviderName       string    `json:"provider_name"`
+	ReviewCount        int       `json:"review_count"`
+	RoomComfortQuality *float64  `json:"room_comfort_quality"`
+	Service            *float64  `json:"service"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	ValueForMoney      *float64  `json:"value_for_money"`
+}
+
+// Ratings are normalized to 0-100 so providers with different scales can be combined. Near-duplicate reviews are counted once
+type HotelStats struct {
+	// Keyed by ISO 639-1 code, unknown for reviews whose language wasn't detected
+	ByLanguage        []Breakdown `json:"by_language"`
+	ByLengthOfStay    []Breakdown `json:"by_length_of_stay"`
+	ByProvider        []Breakdown `json:"by_provider"`
+	ByReviewGroup     []Breakdown `json:"by_review_group"`
+	ByReviewerCountry []Breakdown `json:"by_reviewer_country"`
+	// Keyed by canonical room type category, Unknown for reviews without a room type
+	ByRoomCategory []Breakdown `json:"by_room_category"`
+	HotelID        int64       `json:"hotel_id"`
+	// Language filter the statistics were computed with, absent when unfiltered
+	Language string `json:"language,omitempty"`
+	// Mean normalized rating, 0-100
+	MeanRating *float64 `json:"mean_rating"`
+	// Median normalized rating, 0-100
+	MedianRating    *float64          `json:"median_rating"`
+	RatingHistogram []HistogramBucket `json:"rating_histogram"`
+	ReviewCount     int               `json:"review_count"`
+	Window          DateWindow        `json:"window"`
+}
+
+type HotelStatsResponse struct {
+	Stats HotelStats `json:"stats"`
+}
+
+// Page metadata, empty when there are no records
+type Metadata struct {
+	CurrentPage  int `json:"current_page,omitempty"`
+	FirstPage    int `json:"first_page,omitempty"`
+	LastPage     int `json:"last_page,omitempty"`
+	PageSize     int `json:"page_size,omitempty"`
+	TotalRecords int `json:"total_records,omitempty"`
+}
+
+type ProcessedFile struct {
+	CreatedAt   time.Time `json:"created_at"`
+	ErrorsCount int       `json:"errors_count"`
+	Filename    string    `json:"filename"`
+	ID          int64     `json:"id"`
+	// Last input line whose batch was fully handled, used to resume Interrupted files
+	LastCommittedLine int       `json:"last_committed_line"`
+	ProcessedAt       time.Time `json:"processed_at"`
+	RecordsCount      int       `json:"records_count"`
+	S3Path            string    `json:"s3_path"`
+	Status            string    `json:"status"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type ProcessedFileList struct {
+	Files    []ProcessedFile `json:"files"`
+	Metadata Metadata        `json:"metadata"`
+}
+
+type ProcessedFileResponse struct {
+	File ProcessedFile `json:"file"`
+}
+
+type ProcessingError struct {
+	Category        string    `json:"category"`
+	CreatedAt       time.Time `json:"created_at"`
+	ID              int64     `json:"id"`
+	LineNumber      int       `json:"line_number"`
+	Message         string    `json:"message"`
+	ProcessedFileID int64     `json:"processed_file_id"`
+	RawData         string    `json:"raw_data,omitempty"`
+}
+
+type ProcessingErrorList struct {
+	Errors   []ProcessingError `json:"errors"`
+	Metadata Metadata          `json:"metadata"`
+}
+
+type ProviderDuplication struct {
+	// Duplicates of another provider's review
+	CrossProvider int `json:"cross_provider"`
+	// Reviews that duplicate another review, which is counted instead
+	Duplicates int `json:"duplicates"`
+	// duplicates / review_count
+	DuplicationRate float64 `json:"duplication_rate"`
+	ProviderID      int     `json:"provider_id"`
+	ProviderName    string  `json:"provider_name"`
+	ReviewCount     int     `json:"review_count"`
+}
+
+type ProviderGrades struct {
+	// Grades on a 0-100 scale keyed by category, null when missing
+	Grades       map[string]*float64 `json:"grades"`
+	ProviderID   int                 `json:"provider_id"`
+	ProviderName string              `json:"provider_name"`
+	ReviewCount  int                 `json:"review_count"`
+	Scale        float64             `json:"scale"`
+}
+
+type RatingTrend struct {
+	HotelID  int64         `json:"hotel_id"`
+	Interval string        `json:"interval"`
+	Rolling  int           `json:"rolling"`
+	Series   []TrendSeries `json:"series"`
+	SplitBy  string        `json:"split_by,omitempty"`
+	Window   DateWindow    `json:"window"`
+}
+
+type RatingTrendResponse struct {
+	Trend RatingTrend `json:"trend"`
+}
+
+type ReprocessAccepted struct {
+	Message string `json:"message"`
+	S3Path  string `json:"s3_path"`
+}
+
+type Review struct {
+	CheckInMonthYear string `json:"check_in_month_year"`
+	// Smallest review id of the review's near-duplicate cluster, null until clustered
+	ClusterID             *int64    `json:"cluster_id"`
+	CreatedAt             time.Time `json:"created_at"`
+	FormattedRating       string    `json:"formatted_rating"`
+	FormattedResponseDate string    `json:"formatted_response_date,omitempty"`
+	FormattedReviewDate   string    `json:"formatted_review_date"`
+	HotelID               int64     `json:"hotel_id"`
+	HotelReviewID         int64     `json:"hotel_review_id"`
+	ID                    int64     `json:"id"`
+	IsShowReviewResponse  bool      `json:"is_show_review_response"`
+	// ISO 639-1 code of the language the review was written in, detected offline from its text. Null when the text is too short or no language matches it clearly
+	Language *string `json:"language"`
+	// Confidence of the language detection from 0 to 1
+	LanguageConfidence *float64 `json:"language_confidence"`
+	// Rating on 0-100 of the provider's registered scale
+	NormalizedRating *float64 `json:"normalized_rating"`
+	OriginalComment  string   `json:"original_comment,omitempty"`
+	OriginalTitle    string   `json:"original_title,omitempty"`
+	ProviderID       int      `json:"provider_id"`
+	// Rating on the provider's own scale
+	Rating                  float64   `json:"rating"`
+	RatingText              string    `json:"rating_text"`
+	ResponderName           string    `json:"responder_name,omitempty"`
+	ResponseDateText        string    `json:"response_date_text,omitempty"`
+	ResponseTranslateSource string    `json:"response_translate_source,omitempty"`
+	ReviewComments          string    `json:"review_comments"`
+	ReviewDate              time.Time `json:"review_date"`
+	ReviewNegatives         string    `json:"review_negatives"`
+	ReviewPositives         string    `json:"review_positives"`
+	ReviewProviderLogo      string    `json:"review_provider_logo"`
+	ReviewProviderText      string    `json:"review_provider_text"`
+	ReviewTitle             string    `json:"review_title"`
+	ReviewerCountryID       *int      `json:"reviewer_country_id"`
+	ReviewerCountryName     string    `json:"reviewer_country_name"`
+	ReviewerDisplayName     string    `json:"reviewer_display_name"`
+	ReviewerFlagName        string    `json:"reviewer_flag_name"`
+	ReviewerGroupID         *int      `json:"reviewer_group_id"`
+	ReviewerGroupName       string    `json:"reviewer_group_name"`
+	ReviewerIsExpert        bool      `json:"reviewer_is_expert"`
+	ReviewerLengthOfStay    int       `json:"reviewer_length_of_stay"`
+	ReviewerReviewCount     int       `json:"reviewer_review_count"`
+	ReviewerRoomTypeName    string    `json:"reviewer_room_type_name"`
+	// Canonical category of the room type, absent without one
+	RoomCategory string `json:"room_category,omitempty"`
+	// Room type the review is linked to, null without a room type name
+	RoomTypeID *int `json:"room_type_id"`
+	// Lexicon sentiment of the positives, negatives and comments from -1 to 1, null until scored
+	SentimentScore  *float64  `json:"sentiment_score"`
+	TranslateSource string    `json:"translate_source,omitempty"`
+	TranslateTarget string    `json:"translate_target,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type ReviewList struct {
+	Metadata ReviewListMetadata `json:"metadata"`
+	Reviews  []Review           `json:"reviews"`
+}
+
+type ReviewListMetadata struct {
+	// Pass as cursor to fetch the next page, empty on the last page
+	NextCursor string `json:"next_cursor"`
+	PageSize   int    `json:"page_size"`
+}
+
+type ReviewSearchResult struct {
+	HotelID             int64     `json:"hotel_id"`
+	HotelReviewID       int64     `json:"hotel_review_id"`
+	ID                  int64     `json:"id"`
+	ProviderID          int       `json:"provider_id"`
+	Rank                float64   `json:"rank"`
+	Rating              float64   `json:"rating"`
+	ReviewDate          time.Time `json:"review_date"`
+	ReviewTitle         string    `json:"review_title"`
+	ReviewerDisplayName string    `json:"reviewer_display_name"`
+	// Matching text, HTML escaped, with hits wrapped in <mark></mark>
+	Snippet string `json:"snippet"`
+}
+
+type ReviewSearchResults struct {
+	Metadata Metadata             `json:"metadata"`
+	Results  []ReviewSearchResult `json:"results"`
+}
+
+type ReviewSummary struct {
+	// Mean normalized rating, 0-100
+	AverageRating    *float64   `json:"average_rating"`
+	ExpertReviews    int        `json:"expert_reviews"`
+	FirstReviewDate  *time.Time `json:"first_review_date"`
+	LastReviewDate   *time.Time `json:"last_review_date"`
+	ReviewsResponded int        `json:"reviews_responded"`
+	TotalReviews     int        `json:"total_reviews"`
+}
+
+type RoomType struct {
+	// Canonical category, e.g. Deluxe Double
+	Category  string    `json:"category"`
+	CreatedAt time.Time `json:"created_at"`
+	HotelID   int64     `json:"hotel_id"`
+	ID        int       `json:"id"`
+	// Room type name as the provider gave it
+	Name       string `json:"name"`
+	ProviderID int    `json:"provider_id"`
+	// Room type id the feed sent, 0 when it had none
+	ProviderRoomTypeID int       `json:"provider_room_type_id"`
+	ReviewCount        int       `json:"review_count,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type RoomTypeList struct {
+	RoomTypes []RoomType `json:"room_types"`
+}
+
+type TrendPoint struct {
+	// Change in mean rating from the previous period
+	Delta *float64 `json:"delta"`
+	// Mean normalized rating, 0-100
+	MeanRating *float64 `json:"mean_rating"`
+	// Start of the month or week
+	Period      time.Time `json:"period"`
+	ReviewCount int       `json:"review_count"`
+	// Review weighted mean over the last rolling periods
+	RollingMean *float64 `json:"rolling_mean"`
+}
+
+type TrendSeries struct {
+	// Provider name, reviewer group or all
+	Key    string       `json:"key"`
+	Points []TrendPoint `json:"points"`
+}
+
+// ListAlertsParams holds the optional query parameters of ListAlerts. Nil fields are not sent.
+type ListAlertsParams struct {
+	// Only alerts for this platform hotel ID
+	HotelID *int64
+	// Only alerts of this kind
+	Kind *string
+	// Only alerts of this severity
+	Severity *string
+	// Only open or resolved alerts
+	Status *string
+	// Page number
+	Page *int
+	// Records per page
+	PageSize *int
+	// Sort field, - for descending
+	Sort *string
+}
+
+// ListAlerts calls GET /v1/alerts. List rating anomaly alerts. Requires the reviews:read scope.
+func (c *Client) ListAlerts(ctx context.Context, params *ListAlertsParams) (*AlertList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.HotelID != nil {
+			query.Set("hotel_id", strconv.FormatInt(*params.HotelID, 10))
+		}
+		if params.Kind != nil {
+			query.Set("kind", *params.Kind)
+		}
+		if params.Severity != nil {
+			query.Set("severity", *params.Severity)
+		}
+		if params.Status != nil {
+			query.Set("status", *params.Status)
+		}
+		if params.Page != nil {
+			query.Set("page", strconv.Itoa(*params.Page))
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+		if params.Sort != nil {
+			query.Set("sort", *params.Sort)
+		}
+	}
+	out := new(AlertList)
+	err := c.do(ctx, http.MethodGet, "/v1/alerts", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Healthcheck calls GET /v1/healthcheck. Service status.
+func (c *Client) Healthcheck(ctx context.Context) (*Healthcheck, error) {
+	query := url.Values{}
+	out := new(Healthcheck)
+	err := c.do(ctx, http.MethodGet, "/v1/healthcheck", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListHotelsParams holds the optional query parameters of ListHotels. Nil fields are not sent.
+type ListHotelsParams struct {
+	// Only hotels of this platform
+	Platform *string
+	// Only hotels whose name starts with this prefix, case-insensitive
+	Name *string
+	// Page number
+	Page *int
+	// Records per page
+	PageSize *int
+	// Sort field, - for descending
+	Sort *string
+}
+
+// ListHotels calls GET /v1/hotels. List hotels. Requires the reviews:read scope.
+func (c *Client) ListHotels(ctx context.Context, params *ListHotelsParams) (*HotelList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Platform != nil {
+			query.Set("platform", *params.Platform)
+		}
+		if params.Name != nil {
+			query.Set("name", *params.Name)
+		}
+		if params.Page != nil {
+			query.Set("page", strconv.Itoa(*params.Page))
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+		if params.Sort != nil {
+			query.Set("sort", *params.Sort)
+		}
+	}
+	out := new(HotelList)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetHotel calls GET /v1/hotels/{hotel_id}. Hotel with its provider ratings and review summary. Requires the reviews:read scope.
+func (c *Client) GetHotel(ctx context.Context, hotelID int64) (*HotelDetail, error) {
+	query := url.Values{}
+	out := new(HotelDetail)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10)), query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetHotelAspectsParams holds the optional query parameters of GetHotelAspects. Nil fields are not sent.
+type GetHotelAspectsParams struct {
+	// Only include records on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only include records before this date, YYYY-MM-DD or RFC 3339
+	To *string
+}
+
+// GetHotelAspects calls GET /v1/hotels/{hotel_id}/aspects. Aspects guests mention in the positives and negatives of a hotel's reviews. Requires the reviews:read scope.
+func (c *Client) GetHotelAspects(ctx context.Context, hotelID int64, params *GetHotelAspectsParams) (*AspectListResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+	}
+	out := new(AspectListResponse)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/aspects", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CompareHotelGradesParams holds the optional query parameters of CompareHotelGrades. Nil fields are not sent.
+type CompareHotelGradesParams struct {
+	// Spread above which providers disagree
+	Threshold *float64
+	// Compare with the platform average per category
+	PlatformAverage *bool
+}
+
+// CompareHotelGrades calls GET /v1/hotels/{hotel_id}/grades. Provider category grades side by side on a 0-100 scale. Requires the reviews:read scope.
+func (c *Client) CompareHotelGrades(ctx context.Context, hotelID int64, params *CompareHotelGradesParams) (*GradeComparisonResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Threshold != nil {
+			query.Set("threshold", strconv.FormatFloat(*params.Threshold, 'f', -1, 64))
+		}
+		if params.PlatformAverage != nil {
+			query.Set("platform_average", strconv.FormatBool(*params.PlatformAverage))
+		}
+	}
+	out := new(GradeComparisonResponse)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/grades", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListHotelReviewsParams holds the optional query parameters of ListHotelReviews. Nil fields are not sent.
+type ListHotelReviewsParams struct {
+	// Only reviews from this provider
+	ProviderID *int
+	// Minimum normalized rating on 0-100, inclusive
+	MinRating *float64
+	// Maximum normalized rating on 0-100, inclusive
+	MaxRating *float64
+	// Only reviews on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only reviews before this date, YYYY-MM-DD or RFC 3339
+	To *string
+	// Only reviewers from this country
+	CountryID *int
+	// Only reviewers in this group
+	ReviewGroupID *int
+	// Only reviews for this room type name, as the provider gave it
+	RoomType *string
+	// Only reviews for room types in this canonical category, e.g. Deluxe Double
+	RoomCategory *string
+	// Only reviews by expert reviewers
+	Expert *bool
+	// Only reviews with, or without, a hotel response
+	HasResponse *bool
+	// Only reviews with a sentiment score of at least this, from -1 to 1
+	MinSentiment *float64
+	// Only reviews with a sentiment score of at most this, from -1 to 1
+	MaxSentiment *float64
+	// Only reviews detected in this ISO 639-1 language, e.g. de
+	Language *string
+	// Sort field, - for descending. rating sorts by normalized rating, unrated reviews last in descending order
+	Sort *string
+	// next_cursor of the previous page
+	Cursor *string
+	// Reviews per page
+	PageSize *int
+}
+
+// ListHotelReviews calls GET /v1/hotels/{hotel_id}/reviews. List a hotel's reviews. Requires the reviews:read scope.
+func (c *Client) ListHotelReviews(ctx context.Context, hotelID int64, params *ListHotelReviewsParams) (*ReviewList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.ProviderID != nil {
+			query.Set("provider_id", strconv.Itoa(*params.ProviderID))
+		}
+		if params.MinRating != nil {
+			query.Set("min_rating", strconv.FormatFloat(*params.MinRating, 'f', -1, 64))
+		}
+		if params.MaxRating != nil {
+			query.Set("max_rating", strconv.FormatFloat(*params.MaxRating, 'f', -1, 64))
+		}
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+		if params.CountryID != nil {
+			query.Set("country_id", strconv.Itoa(*params.CountryID))
+		}
+		if params.ReviewGroupID != nil {
+			query.Set("review_group_id", strconv.Itoa(*params.ReviewGroupID))
+		}
+		if params.RoomType != nil {
+			query.Set("room_type", *params.RoomType)
+		}
+		if params.RoomCategory != nil {
+			query.Set("room_category", *params.RoomCategory)
+		}
+		if params.Expert != nil {
+			query.Set("expert", strconv.FormatBool(*params.Expert))
+		}
+		if params.HasResponse != nil {
+			query.Set("has_response", strconv.FormatBool(*params.HasResponse))
+		}
+		if params.MinSentiment != nil {
+			query.Set("min_sentiment", strconv.FormatFloat(*params.MinSentiment, 'f', -1, 64))
+		}
+		if params.MaxSentiment != nil {
+			query.Set("max_sentiment", strconv.FormatFloat(*params.MaxSentiment, 'f', -1, 64))
+		}
+		if params.Language != nil {
+			query.Set("language", *params.Language)
+		}
+		if params.Sort != nil {
+			query.Set("sort", *params.Sort)
+		}
+		if params.Cursor != nil {
+			query.Set("cursor", *params.Cursor)
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+	}
+	out := new(ReviewList)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/reviews", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListHotelRoomTypes calls GET /v1/hotels/{hotel_id}/room-types. Room types of a hotel as each provider names them, with their canonical category. Requires the reviews:read scope.
+func (c *Client) ListHotelRoomTypes(ctx context.Context, hotelID int64) (*RoomTypeList, error) {
+	query := url.Values{}
+	out := new(RoomTypeList)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/room-types", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetHotelStatsParams holds the optional query parameters of GetHotelStats. Nil fields are not sent.
+type GetHotelStatsParams struct {
+	// Only include records on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only include records before this date, YYYY-MM-DD or RFC 3339
+	To *string
+	// Only reviews detected in this ISO 639-1 language, e.g. de
+	Language *string
+}
+
+// GetHotelStats calls GET /v1/hotels/{hotel_id}/stats. Review statistics and rating distribution of a hotel. Requires the reviews:read scope.
+func (c *Client) GetHotelStats(ctx context.Context, hotelID int64, params *GetHotelStatsParams) (*HotelStatsResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+		if params.Language != nil {
+			query.Set("language", *params.Language)
+		}
+	}
+	out := new(HotelStatsResponse)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/stats", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetHotelTrendsParams holds the optional query parameters of GetHotelTrends. Nil fields are not sent.
+type GetHotelTrendsParams struct {
+	// Bucket size, on review_date
+	Interval *string
+	// Return one series per provider or reviewer group instead of a single all series
+	Split *string
+	// Only include records on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only include records before this date, YYYY-MM-DD or RFC 3339
+	To *string
+	// Number of periods in the rolling average, including the current one
+	Rolling *int
+}
+
+// GetHotelTrends calls GET /v1/hotels/{hotel_id}/trends. Average rating and review volume of a hotel per month or week. Requires the reviews:read scope.
+func (c *Client) GetHotelTrends(ctx context.Context, hotelID int64, params *GetHotelTrendsParams) (*RatingTrendResponse, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Interval != nil {
+			query.Set("interval", *params.Interval)
+		}
+		if params.Split != nil {
+			query.Set("split", *params.Split)
+		}
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+		if params.Rolling != nil {
+			query.Set("rolling", strconv.Itoa(*params.Rolling))
+		}
+	}
+	out := new(RatingTrendResponse)
+	err := c.do(ctx, http.MethodGet, "/v1/hotels/"+url.PathEscape(strconv.FormatInt(hotelID, 10))+"/trends", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProcessedFilesParams holds the optional query parameters of ListProcessedFiles. Nil fields are not sent.
+type ListProcessedFilesParams struct {
+	// Only files with this status
+	Status *string
+	// Only files processed on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only files processed before this date, YYYY-MM-DD or RFC 3339
+	To *string
+	// Page number
+	Page *int
+	// Records per page
+	PageSize *int
+	// Sort field, - for descending
+	Sort *string
+}
+
+// ListProcessedFiles calls GET /v1/ingest/files. List processed files. Requires the ingest:admin scope.
+func (c *Client) ListProcessedFiles(ctx context.Context, params *ListProcessedFilesParams) (*ProcessedFileList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Status != nil {
+			query.Set("status", *params.Status)
+		}
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+		if params.Page != nil {
+			query.Set("page", strconv.Itoa(*params.Page))
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+		if params.Sort != nil {
+			query.Set("sort", *params.Sort)
+		}
+	}
+	out := new(ProcessedFileList)
+	err := c.do(ctx, http.MethodGet, "/v1/ingest/files", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetProcessedFile calls GET /v1/ingest/files/{id}. Processed file with its counts and resume point. Requires the ingest:admin scope.
+func (c *Client) GetProcessedFile(ctx context.Context, id int64) (*ProcessedFileResponse, error) {
+	query := url.Values{}
+	out := new(ProcessedFileResponse)
+	err := c.do(ctx, http.MethodGet, "/v1/ingest/files/"+url.PathEscape(strconv.FormatInt(id, 10)), query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProcessedFileErrorsParams holds the optional query parameters of ListProcessedFileErrors. Nil fields are not sent.
+type ListProcessedFileErrorsParams struct {
+	// Only errors of this category
+	Category *string
+	// Page number
+	Page *int
+	// Errors per page
+	PageSize *int
+}
+
+// ListProcessedFileErrors calls GET /v1/ingest/files/{id}/errors. Record errors persisted for a processed file. Requires the ingest:admin scope.
+func (c *Client) ListProcessedFileErrors(ctx context.Context, id int64, params *ListProcessedFileErrorsParams) (*ProcessingErrorList, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Category != nil {
+			query.Set("category", *params.Category)
+		}
+		if params.Page != nil {
+			query.Set("page", strconv.Itoa(*params.Page))
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+	}
+	out := new(ProcessingErrorList)
+	err := c.do(ctx, http.MethodGet, "/v1/ingest/files/"+url.PathEscape(strconv.FormatInt(id, 10))+"/errors", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReprocessFile calls POST /v1/ingest/files/{id}/reprocess. Re-import a file in the background. Requires the ingest:admin scope.
+func (c *Client) ReprocessFile(ctx context.Context, id int64) (*ReprocessAccepted, error) {
+	query := url.Values{}
+	out := new(ReprocessAccepted)
+	err := c.do(ctx, http.MethodPost, "/v1/ingest/files/"+url.PathEscape(strconv.FormatInt(id, 10))+"/reprocess", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOpenAPI calls GET /v1/openapi.json. This document.
+func (c *Client) GetOpenAPI(ctx context.Context) (json.RawMessage, error) {
+	query := url.Values{}
+	var out json.RawMessage
+	err := c.do(ctx, http.MethodGet, "/v1/openapi.json", query, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetDuplicationReportParams holds the optional query parameters of GetDuplicationReport. Nil fields are not sent.
+type GetDuplicationReportParams struct {
+	// Only reviews of this platform hotel ID
+	HotelID *int64
+	// Only include records on or after this date, YYYY-MM-DD or RFC 3339
+	From *string
+	// Only include records before this date, YYYY-MM-DD or RFC 3339
+	To *string
+}
+
+// GetDuplicationReport calls GET /v1/reports/duplication. Near-duplicate review rate per provider. Requires the reviews:read scope.
+func (c *Client) GetDuplicationReport(ctx context.Context, params *GetDuplicationReportParams) (*DuplicationReport, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.HotelID != nil {
+			query.Set("hotel_id", strconv.FormatInt(*params.HotelID, 10))
+		}
+		if params.From != nil {
+			query.Set("from", *params.From)
+		}
+		if params.To != nil {
+			query.Set("to", *params.To)
+		}
+	}
+	out := new(DuplicationReport)
+	err := c.do(ctx, http.MethodGet, "/v1/reports/duplication", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SearchReviewsParams holds the optional query parameters of SearchReviews. Nil fields are not sent.
+type SearchReviewsParams struct {
+	// Language of the search terms
+	Lang *string
+	// Only reviews of this hotel
+	HotelID *int64
+	// Page number
+	Page *int
+	// Records per page
+	PageSize *int
+}
+
+// SearchReviews calls GET /v1/reviews/search. Ranked full-text search over review text. Requires the reviews:read scope.
+func (c *Client) SearchReviews(ctx context.Context, q string, params *SearchReviewsParams) (*ReviewSearchResults, error) {
+	query := url.Values{}
+	query.Set("q", q)
+	if params != nil {
+		if params.Lang != nil {
+			query.Set("lang", *params.Lang)
+		}
+		if params.HotelID != nil {
+			query.Set("hotel_id", strconv.FormatInt(*params.HotelID, 10))
+		}
+		if params.Page != nil {
+			query.Set("page", strconv.Itoa(*params.Page))
+		}
+		if params.PageSize != nil {
+			query.Set("page_size", strconv.Itoa(*params.PageSize))
+		}
+	}
+	out := new(ReviewSearchResults)
+	err := c.do(ctx, http.MethodGet, "/v1/reviews/search", query, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+// Package client is a typed Go client for the review system HTTP API.
+//
+// The request methods and response types in client.gen.go are generated
+// from the API's OpenAPI document (internal/api/openapi.json). Regenerate
+// them with go generate after changing the document.
+package client
+
+//go:generate go run ../cmd/openapi client -out client.gen.go
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the review API with an API key
+type Client struct {
+	BaseURL    string       // e.g. http://localhost:4000
+	APIKey     string       // Sent as a bearer token, leave empty for public endpoints
+	HTTPClient *http.Client // http.DefaultClient when nil
+}
+
+func New(baseURL, apiKey string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), APIKey: apiKey}
+}
+
+// Error is a non-2xx response from the API
+type Error struct {
+	StatusCode int
+	Message    string            // Set for plain errors
+	Fields     map[string]string // Set for validation errors, keyed by parameter
+	RetryAfter time.Duration     // Set for 429 responses
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("review api: %d %s", e.StatusCode, e.Message)
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for field, message := range e.Fields {
+		fields = append(fields, field+" "+message)
+	}
+	slices.Sort(fields)
+
+	return fmt.Sprintf("review api: %d %s", e.StatusCode, strings.Join(fields, "; "))
+}
+
+// do sends a request and decodes a 2xx JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, out any) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return decodeError(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil || json.Unmarshal(b, &body) != nil {
+		return apiErr
+	}
+
+	if json.Unmarshal(body.Error, &apiErr.Message) != nil {
+		json.Unmarshal(body.Error, &apiErr.Fields)
+	}
+
+	return apiErr
+}
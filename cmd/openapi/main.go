@@ -0,0 +1,74 @@
+// Command openapi keeps the API's OpenAPI document, its routes and the
+// generated Go client in step.
+//
+//	go run ./cmd/openapi check               fail if the document and routes or client disagree
+//	go run ./cmd/openapi client -out FILE    regenerate the Go client
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mahesh-singh/review-system/internal/api"
+	"github.com/mahesh-singh/review-system/internal/openapi"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: openapi check|client [flags]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	out := fs.String("out", "client/client.gen.go", "Generated client file")
+	pkg := fs.String("pkg", "client", "Package name of the generated client")
+	fs.Parse(os.Args[2:])
+
+	doc, err := openapi.Load(api.OpenAPISpec())
+	if err != nil {
+		fatal(err)
+	}
+
+	src, err := openapi.GenerateClient(doc, *pkg)
+	if err != nil {
+		fatal(err)
+	}
+
+	switch os.Args[1] {
+	case "check":
+		routes := []openapi.Route{}
+		for _, route := range api.Routes() {
+			routes = append(routes, openapi.Route{Method: route.Method, Pattern: route.Pattern, Scope: route.Scope})
+		}
+
+		errs := openapi.Check(doc, routes)
+
+		current, err := os.ReadFile(*out)
+		if err != nil || !bytes.Equal(current, src) {
+			errs = append(errs, fmt.Errorf("%s is out of date, run go generate ./client", *out))
+		}
+
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		fmt.Printf("openapi: %d operations match the routes and client\n", len(doc.Operations()))
+
+	case "client":
+		if err := os.WriteFile(*out, src, 0o644); err != nil {
+			fatal(err)
+		}
+
+	default:
+		fatal(fmt.Errorf("unknown command %q", os.Args[1]))
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "openapi:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// detectBatchSize is the number of hotels `alerts detect` checks at a time
+const detectBatchSize = 500
+
+// anomalyOptions returns the detector settings from the -anomaly-* flags
+func (app *application) anomalyOptions() (data.AnomalyOptions, error) {
+	opts := data.DefaultAnomalyOptions()
+	opts.RecentDays = app.config.anomalies.recentDays
+	opts.BaselineDays = app.config.anomalies.baselineDays
+	opts.ZThreshold = app.config.anomalies.zThreshold
+	opts.ScoreDrop = app.config.anomalies.scoreDrop
+
+	v := validator.New()
+	if data.ValidateAnomalyOptions(v, opts); !v.Valid() {
+		return opts, fmt.Errorf("invalid anomaly settings: %v", v.Errors)
+	}
+
+	return opts, nil
+}
+
+// alertsDetect runs anomaly detection for -hotel-id, or for every hotel
+func (app *application) alertsDetect(ctx context.Context) int {
+	opts, err := app.anomalyOptions()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	hotelIDs := []int64{app.config.report.hotelID}
+	if app.config.report.hotelID <= 0 {
+		hotelIDs, err = app.models.Hotel.GetIDs(ctx)
+		if err != nil {
+			app.logger.Error("error listing hotels", slog.String("error", err.Error()))
+			return exitFatal
+		}
+	}
+
+	open := 0
+	for start := 0; start < len(hotelIDs); start += detectBatchSize {
+		if ctx.Err() != nil {
+			return exitInterrupted
+		}
+
+		end := min(start+detectBatchSize, len(hotelIDs))
+		alerts, err := app.models.Alerts.Detect(ctx, hotelIDs[start:end], opts)
+		if err != nil {
+			app.logger.Error("error detecting anomalies", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		open += len(alerts)
+	}
+
+	app.logger.Info("anomaly detection complete", slog.Int("hotels", len(hotelIDs)), slog.Int("open_alerts", open))
+	return exitSuccess
+}
+
+// alertsList prints the alerts with -status, for -hotel-id if set
+func (app *application) alertsList(ctx context.Context) int {
+	filter := data.AlertFilter{
+		Status: app.config.alerts.status,
+		Filters: data.Filters{
+			Page:         1,
+			PageSize:     100,
+			Sort:         "-detected_at",
+			SortSafelist: []string{"-detected_at"},
+		},
+	}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+	if app.config.report.hotelID > 0 {
+		filter.HotelID = &app.config.report.hotelID
+	}
+
+	v := validator.New()
+	if data.ValidateAlertFilter(v, filter); !v.Valid() {
+		app.logger.Error("invalid filter", slog.Any("errors", v.Errors))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tHOTEL\tPROVIDER\tKIND\tSEVERITY\tDETECTED\tSTATUS\tMESSAGE")
+
+	for {
+		alerts, metadata, err := app.models.Alerts.GetAll(ctx, filter)
+		if err != nil {
+			app.logger.Error("error listing alerts", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		for _, alert := range alerts {
+			provider := "-"
+			if alert.ProviderID != nil {
+				provider = strconv.Itoa(*alert.ProviderID)
+			}
+			status := data.AlertStatusOpen
+			if alert.ResolvedAt != nil {
+				status = data.AlertStatusResolved
+			}
+
+			fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				alert.ID, alert.HotelID, provider, alert.Kind, alert.Severity,
+				formatTime(&alert.DetectedAt, "-"), status, alert.Message)
+		}
+
+		if filter.Page >= metadata.LastPage {
+			break
+		}
+		filter.Page++
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing alerts", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
+
+// alertsResolve closes the alert -id
+func (app *application) alertsResolve(ctx context.Context) int {
+	if app.config.id <= 0 {
+		app.logger.Error("-id must be provided")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	err = app.models.Alerts.Resolve(ctx, app.config.id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.logger.Error("alert not found or already resolved", slog.Int64("id", app.config.id))
+		default:
+			app.logger.Error("error resolving alert", slog.String("error", err.Error()))
+		}
+		return exitFatal
+	}
+
+	app.logger.Info("alert resolved", slog.Int64("id", app.config.id))
+	return exitSuccess
+}
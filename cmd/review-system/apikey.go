@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// apiKeyCreate creates a key for -owner with -scopes and prints it. The
+// plaintext key is only shown here; the database stores its hash.
+func (app *application) apiKeyCreate(ctx context.Context) int {
+	scopes := []string{}
+	for _, scope := range strings.Split(app.config.apiKey.scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	key, err := app.models.APIKeys.New(app.config.apiKey.owner, scopes, app.config.apiKey.ttl, app.config.apiKey.limits)
+	if err != nil {
+		app.logger.Error("error creating api key", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	if err := printJSON(key); err != nil {
+		app.logger.Error("error writing api key", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
+
+// apiKeyList prints every key, including revoked and expired ones
+func (app *application) apiKeyList(ctx context.Context) int {
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	keys, err := app.models.APIKeys.GetAll(ctx)
+	if err != nil {
+		app.logger.Error("error listing api keys", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPREFIX\tOWNER\tSCOPES\tLIMITS\tEXPIRES\tLAST USED\tSTATUS")
+	for _, key := range keys {
+		status := "active"
+		switch {
+		case key.RevokedAt != nil:
+			status = "revoked"
+		case key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()):
+			status = "expired"
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.Prefix, key.Owner, strings.Join(key.Scopes, ","), formatLimits(key.Limits),
+			formatTime(key.ExpiresAt, "never"), formatTime(key.LastUsedAt, "-"), status)
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing api keys", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
+
+// apiKeyRevoke revokes the key -id. Requests using it fail from then on.
+func (app *application) apiKeyRevoke(ctx context.Context) int {
+	if app.config.id <= 0 {
+		app.logger.Error("-id must be provided")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	err = app.models.APIKeys.Revoke(ctx, app.config.id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.logger.Error("api key not found or already revoked", slog.Int64("id", app.config.id))
+		default:
+			app.logger.Error("error revoking api key", slog.String("error", err.Error()))
+		}
+		return exitFatal
+	}
+
+	app.logger.Info("api key revoked", slog.Int64("id", app.config.id))
+	return exitSuccess
+}
+
+// apiKeyLimits replaces the rate limits of the key -id with -key-rps,
+// -key-burst and -key-daily-quota. Omitted limits revert to the default.
+func (app *application) apiKeyLimits(ctx context.Context) int {
+	if app.config.id <= 0 {
+		app.logger.Error("-id must be provided")
+		return exitFatal
+	}
+
+	v := validator.New()
+	if data.ValidateRateLimits(v, app.config.apiKey.limits); !v.Valid() {
+		app.logger.Error("invalid limits", slog.Any("errors", v.Errors))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	err = app.models.APIKeys.SetLimits(ctx, app.config.id, app.config.apiKey.limits)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.logger.Error("api key not found or revoked", slog.Int64("id", app.config.id))
+		default:
+			app.logger.Error("error setting api key limits", slog.String("error", err.Error()))
+		}
+		return exitFatal
+	}
+
+	app.logger.Info("api key limits set", slog.Int64("id", app.config.id), slog.String("limits", formatLimits(app.config.apiKey.limits)))
+	return exitSuccess
+}
+
+// formatLimits shows the per-key overrides, "default" where there are none
+func formatLimits(limits data.RateLimits) string {
+	rps, burst, quota := "default", "default", "default"
+	if limits.RPS != nil {
+		rps = strconv.FormatFloat(*limits.RPS, 'f', -1, 64)
+	}
+	if limits.Burst != nil {
+		burst = strconv.Itoa(*limits.Burst)
+	}
+	if limits.DailyQuota != nil {
+		quota = strconv.Itoa(*limits.DailyQuota)
+		if *limits.DailyQuota == 0 {
+			quota = "none"
+		}
+	}
+
+	return fmt.Sprintf("%s rps, burst %s, quota %s", rps, burst, quota)
+}
+
+func formatTime(t *time.Time, zero string) string {
+	if t == nil {
+		return zero
+	}
+	return t.Format(time.RFC3339)
+}
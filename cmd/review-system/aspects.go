@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/aspects"
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// aspectsBackfill tags the reviews that were not tagged with the current
+// dictionary, in batches of -batch-size. Every batch commits on its own, so
+// an interrupted run resumes where it stopped when rerun.
+func (app *application) aspectsBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	dictionary, err := aspects.Load(app.config.aspectsPath)
+	if err != nil {
+		app.logger.Error("error loading aspect dictionary", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	start := time.Now()
+	var afterID int64
+	var tagged int
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("aspects backfill interrupted", slog.Int("tagged", tagged), slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		texts, err := app.models.ReviewAspects.ListUntagged(ctx, dictionary.Version, app.config.backfill.rescore, afterID, app.config.backfill.batchSize)
+		if err != nil {
+			app.logger.Error("error listing reviews to tag", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if len(texts) == 0 {
+			break
+		}
+
+		batch := make([]data.TaggedReview, len(texts))
+		for i, text := range texts {
+			batch[i] = data.TaggedReview{
+				ReviewID: text.ID,
+				Mentions: dictionary.Extract(text.Positives, text.Negatives),
+			}
+		}
+
+		if err := app.storeAspects(ctx, db, dictionary.Version, batch); err != nil {
+			app.logger.Error("error storing review aspects", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		tagged += len(texts)
+		afterID = texts[len(texts)-1].ID
+	}
+
+	app.logger.Info("aspects backfill complete",
+		slog.Int("tagged", tagged),
+		slog.String("version", dictionary.Version),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
+
+func (app *application) storeAspects(ctx context.Context, db *sql.DB, version string, batch []data.TaggedReview) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := (data.ReviewAspectModel{DB: tx}).Replace(ctx, version, batch); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/dedupe"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// dedupeOptions returns the near-duplicate settings from the -dedupe-* flags
+func (app *application) dedupeOptions() (dedupe.Options, error) {
+	opts := dedupe.Options{
+		MaxDistance: app.config.dedupe.distance,
+		DateSlack:   app.config.dedupe.dateSlack,
+	}
+
+	v := validator.New()
+	if dedupe.ValidateOptions(v, opts); !v.Valid() {
+		return opts, fmt.Errorf("invalid dedupe settings: %v", v.Errors)
+	}
+
+	return opts, nil
+}
+
+// dedupeBackfill signs the reviews whose text has no signature from the
+// current dedupe.Version, in batches of -batch-size, then reclusters every
+// hotel, or only -hotel-id. Signing batches commit on their own and
+// reclustering is idempotent, so an interrupted run can simply be rerun.
+func (app *application) dedupeBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	opts, err := app.dedupeOptions()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	start := time.Now()
+	var afterID, signed int64
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("dedupe backfill interrupted", slog.Int64("signed", signed), slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		texts, err := app.models.Review.ListUnsigned(ctx, dedupe.Version, app.config.backfill.rescore, afterID, app.config.backfill.batchSize)
+		if err != nil {
+			app.logger.Error("error listing reviews to sign", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if len(texts) == 0 {
+			break
+		}
+
+		signatures := make([]data.ReviewSimhash, len(texts))
+		for i, text := range texts {
+			signatures[i].ID = text.ID
+			if signature, ok := dedupe.Sign(text.Positives, text.Negatives, text.Comments); ok {
+				value := int64(signature)
+				signatures[i].Simhash = &value
+			}
+		}
+
+		updated, err := app.models.Review.SetSimhashes(ctx, dedupe.Version, signatures)
+		if err != nil {
+			app.logger.Error("error storing signatures", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		signed += updated
+		afterID = texts[len(texts)-1].ID
+	}
+
+	hotelIDs := []int64{app.config.report.hotelID}
+	if app.config.report.hotelID <= 0 {
+		hotelIDs, err = app.models.Hotel.GetIDs(ctx)
+		if err != nil {
+			app.logger.Error("error listing hotels", slog.String("error", err.Error()))
+			return exitFatal
+		}
+	}
+
+	var days int
+	for i, hotelID := range hotelIDs {
+		if ctx.Err() != nil {
+			app.logger.Warn("dedupe backfill interrupted", slog.Int64("signed", signed), slog.Int("hotels_clustered", i))
+			return exitInterrupted
+		}
+
+		changed, err := app.reclusterHotel(ctx, db, hotelID, opts)
+		if err != nil {
+			app.logger.Error("error clustering duplicate reviews", slog.Int64("hotel_id", hotelID), slog.String("error", err.Error()))
+			return exitFatal
+		}
+		days += changed
+	}
+
+	app.logger.Info("dedupe backfill complete",
+		slog.Int64("signed", signed),
+		slog.Int("hotels", len(hotelIDs)),
+		slog.Int("days_refreshed", days),
+		slog.Int("version", dedupe.Version),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
+
+// reclusterHotel reclusters one hotel and refreshes the rollup days that
+// changed in a transaction, returning the number of days refreshed
+func (app *application) reclusterHotel(ctx context.Context, db *sql.DB, hotelID int64, opts dedupe.Options) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	keys, err := data.DuplicateModel{DB: tx}.Recluster(ctx, hotelID, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := (data.DailyStatsModel{DB: tx}).Refresh(ctx, keys); err != nil {
+		return 0, err
+	}
+
+	return len(keys), tx.Commit()
+}
+
+// dedupeReport prints the duplication rate of each provider, for -hotel-id
+// if set and within -from/-to
+func (app *application) dedupeReport(ctx context.Context) int {
+	window, err := app.dateWindow()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	var hotelID *int64
+	if app.config.report.hotelID > 0 {
+		hotelID = &app.config.report.hotelID
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	report, err := app.models.Analytics.DuplicationByProvider(ctx, hotelID, window)
+	if err != nil {
+		app.logger.Error("error computing duplication report", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tREVIEWS\tDUPLICATES\tCROSS-PROVIDER\tRATE")
+	for _, d := range report {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f%%\n",
+			d.ProviderName, d.ReviewCount, d.Duplicates, d.CrossProvider, d.DuplicationRate*100)
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing duplication report", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// grades prints the cross-provider grade comparison of -hotel-id
+func (app *application) grades(ctx context.Context) int {
+	if app.config.report.hotelID <= 0 {
+		app.logger.Error("-hotel-id must be provided")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	hotel, err := app.models.Hotel.Get(ctx, app.config.report.hotelID)
+	if err != nil {
+		app.logger.Error("error loading hotel", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	comparison, err := app.models.Analytics.CompareProviderGrades(ctx, hotel, data.GradeComparisonOptions{
+		Threshold:       app.config.report.threshold,
+		Scales:          app.config.gradeScales,
+		PlatformAverage: true,
+	})
+	if err != nil {
+		app.logger.Error("error comparing grades", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	printGradeComparison(hotel, comparison)
+
+	return exitSuccess
+}
+
+func printGradeComparison(hotel *data.Hotel, comparison *data.GradeComparison) {
+	fmt.Printf("%s (%d, %s), grades on a 0-100 scale, disagreement above %.1f\n\n",
+		hotel.Name, hotel.HotelID, hotel.Platform, comparison.Threshold)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	header := []string{"CATEGORY"}
+	for _, provider := range comparison.Providers {
+		header = append(header, strings.ToUpper(provider.ProviderName))
+	}
+	header = append(header, "SPREAD", "PLATFORM", "DELTA", "")
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, c := range comparison.Categories {
+		row := []string{c.Category}
+		for _, provider := range comparison.Providers {
+			row = append(row, formatScore(c.Scores[provider.ProviderName]))
+		}
+
+		flag := ""
+		if c.Disagreement {
+			flag = "DISAGREE"
+		}
+		row = append(row, formatScore(c.Spread), formatScore(c.PlatformAverage), formatScore(c.DeltaFromPlatform), flag)
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	tw.Flush()
+}
+
+func formatScore(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*score, 'f', 1, 64)
+}
+
+// parseScales parses "Provider=scale,..." into a map, as used by -grade-scales
+func parseScales(s string) (map[string]float64, error) {
+	scales := make(map[string]float64)
+	if s == "" {
+		return scales, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid scale %q, expected Provider=scale", pair)
+		}
+
+		scale, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || scale <= 0 {
+			return nil, fmt.Errorf("invalid scale %q, must be a positive number", value)
+		}
+		scales[strings.TrimSpace(name)] = scale
+	}
+
+	return scales, nil
+}
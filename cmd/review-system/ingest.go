@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/aspects"
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+// ingest performs the import and returns the process exit code. Cancelling
+// ctx stops new files and batches from starting.
+func (app *application) ingest(ctx context.Context) int {
+	startedAt := time.Now()
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		return app.fatal(startedAt, "error in connecting database", err)
+	}
+
+	app.logger.Info("database connection tested")
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	sources, err := app.loadSources()
+	if err != nil {
+		return app.fatal(startedAt, "error loading sources", err)
+	}
+
+	files, err := app.listSources(ctx, sources)
+	if err != nil {
+		return app.fatal(startedAt, "error while listing the file", err)
+	}
+
+	anomalies, err := app.anomalyOptions()
+	if err != nil {
+		return app.fatal(startedAt, "error configuring anomaly detection", err)
+	}
+
+	dedupeOptions, err := app.dedupeOptions()
+	if err != nil {
+		return app.fatal(startedAt, "error configuring duplicate detection", err)
+	}
+
+	dictionary, err := aspects.Load(app.config.aspectsPath)
+	if err != nil {
+		return app.fatal(startedAt, "error loading aspect dictionary", err)
+	}
+
+	processingConfig := jsonl_processing.DefaultProcessingConfig()
+	processingConfig.ShutdownTimeout = app.config.shutdownTimeout
+	processingConfig.FailFast = app.config.failFast
+	processingConfig.Aspects = dictionary
+	processingConfig.Anomalies = anomalies
+	processingConfig.DetectAnomalies = app.config.anomalies.detect
+	processingConfig.Dedupe = dedupeOptions
+	processingConfig.ClusterDuplicates = app.config.dedupe.cluster
+
+	jsonl_processing_service := jsonl_processing.NewJSONLProcessingService(db, processingConfig, app.logger)
+
+	processing_result, err := jsonl_processing_service.ProcessMultipleFiles(ctx, files, app.config.concurrency)
+
+	if err != nil {
+		app.logger.Error("Error in processing json", slog.String("error", err.Error()))
+	}
+
+	interrupted := ctx.Err() != nil
+	if interrupted {
+		app.logger.Warn("shutdown signal received, interrupted files will resume on the next run")
+	}
+
+	summary := jsonl_processing.NewRunSummary(startedAt, processing_result)
+	exitCode := app.setOutcome(summary, interrupted)
+	if err != nil {
+		summary.Error = err.Error()
+	}
+
+	app.logger.Info("Processing result",
+		slog.String("outcome", summary.Outcome),
+		slog.Int("files", summary.TotalFiles),
+		slog.Int("success_records", summary.SuccessRecords),
+		slog.Int("error_records", summary.ErrorRecords))
+
+	if err := app.writeSummary(summary); err != nil {
+		app.logger.Error("error writing run summary", slog.String("error", err.Error()))
+	}
+
+	return exitCode
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/langid"
+)
+
+// languageBackfill detects the language of the reviews that have none, or
+// one detected by an older langid version, in batches of -batch-size. Every
+// batch commits on its own, so an interrupted run resumes where it stopped
+// when rerun.
+func (app *application) languageBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	start := time.Now()
+	var afterID, detected int64
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("language backfill interrupted", slog.Int64("detected", detected), slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		texts, err := app.models.Review.ListUndetected(ctx, langid.Version, app.config.backfill.rescore, afterID, app.config.backfill.batchSize)
+		if err != nil {
+			app.logger.Error("error listing reviews to detect", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if len(texts) == 0 {
+			break
+		}
+
+		languages := make([]data.ReviewLanguage, len(texts))
+		for i, text := range texts {
+			languages[i].ID = text.ID
+			if result, ok := langid.DetectReview(text.OriginalComment, text.Comments, text.Positives, text.Negatives); ok {
+				languages[i].Language = result.Language
+				languages[i].Confidence = result.Confidence
+			}
+		}
+
+		updated, err := app.models.Review.SetLanguages(ctx, langid.Version, languages)
+		if err != nil {
+			app.logger.Error("error storing languages", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		detected += updated
+		afterID = texts[len(texts)-1].ID
+	}
+
+	app.logger.Info("language backfill complete",
+		slog.Int64("detected", detected),
+		slog.Int("version", langid.Version),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
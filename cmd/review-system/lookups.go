@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// lookupsChanges prints the provider, country, review group and room type
+// names platforms changed, newest first, only of -lookup if set
+func (app *application) lookupsChanges(ctx context.Context) int {
+	v := validator.New()
+	if app.config.lookup != "" {
+		v.Check(validator.PermittedValue(app.config.lookup, data.LookupProvider, data.LookupCountry, data.LookupReviewGroup, data.LookupRoomType),
+			"lookup", "must be provider, country, review_group or room_type")
+	}
+	if !v.Valid() {
+		app.logger.Error("invalid lookup", slog.Any("errors", v.Errors))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	changes, err := app.models.Lookups.NameChanges(ctx, app.config.lookup)
+	if err != nil {
+		app.logger.Error("error listing name changes", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGED\tLOOKUP\tID\tPLATFORM\tEXTERNAL ID\tOLD NAME\tNEW NAME")
+	for _, change := range changes {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%s\t%s\n",
+			formatTime(&change.ChangedAt, "-"), change.Lookup, change.LookupID, change.Platform, change.ExternalID, change.OldName, change.NewName)
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing name changes", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
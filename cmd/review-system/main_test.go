@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantName string
+		wantArgs int
+	}{
+		{nil, "ingest", 0},
+		{[]string{"-concurrency", "2"}, "ingest", 2},
+		{[]string{"serve", "-port", "8080"}, "serve", 2},
+		{[]string{"apikey", "create", "-owner", "bi"}, "apikey create", 2},
+	}
+
+	for _, tt := range tests {
+		name, args := parseCommand(tt.args)
+		if name != tt.wantName || len(args) != tt.wantArgs {
+			t.Errorf("parseCommand(%q) = %q, %q, want %q with %d args", tt.args, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		wantErr error
+		check   func(t *testing.T, cfg *appConfig)
+	}{
+		{
+			name:    "defaults",
+			command: "ingest",
+			check: func(t *testing.T, cfg *appConfig) {
+				if cfg.concurrency != 5 || cfg.failThreshold != 10 || !cfg.dedupe.cluster {
+					t.Errorf("concurrency %d, fail threshold %v, dedupe %v, want the defaults", cfg.concurrency, cfg.failThreshold, cfg.dedupe.cluster)
+				}
+			},
+		},
+		{
+			name:    "own flags",
+			command: "stats",
+			args:    []string{"-hotel-id", "5", "-from", "2024-01-01", "-language", "de"},
+			check: func(t *testing.T, cfg *appConfig) {
+				if cfg.report.hotelID != 5 || cfg.report.from != "2024-01-01" || cfg.report.language != "de" {
+					t.Errorf("report = %+v", cfg.report)
+				}
+			},
+		},
+		{
+			name:    "flag of another command",
+			command: "apikey create",
+			args:    []string{"-port", "8080"},
+			wantErr: errInvalidFlags,
+		},
+		{
+			name:    "command after the flags",
+			command: "ingest",
+			args:    []string{"-concurrency", "2", "stats"},
+			wantErr: errInvalidFlags,
+		},
+		{
+			name:    "unknown command",
+			command: "rollup",
+			wantErr: errors.New(`unknown command "rollup"`),
+		},
+		{
+			name:    "help",
+			command: "serve",
+			args:    []string{"-h"},
+			wantErr: flag.ErrHelp,
+		},
+		{
+			name:    "unset key limits",
+			command: "apikey limits",
+			args:    []string{"-id", "3", "-key-burst", "5"},
+			check: func(t *testing.T, cfg *appConfig) {
+				limits := cfg.apiKey.limits
+				if cfg.id != 3 || limits.RPS != nil || limits.Burst == nil || *limits.Burst != 5 || limits.DailyQuota != nil {
+					t.Errorf("id %d, limits %s, want 3 with only burst 5 set", cfg.id, formatLimits(limits))
+				}
+			},
+		},
+		{
+			name:    "quota opt-out",
+			command: "apikey create",
+			args:    []string{"-owner", "bi", "-key-daily-quota", "0"},
+			check: func(t *testing.T, cfg *appConfig) {
+				if quota := cfg.apiKey.limits.DailyQuota; quota == nil || *quota != 0 {
+					t.Errorf("limits %s, want no quota", formatLimits(cfg.apiKey.limits))
+				}
+			},
+		},
+		{
+			name:    "invalid value",
+			command: "apikey limits",
+			args:    []string{"-key-rps", "fast"},
+			wantErr: errInvalidFlags,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, cfg, err := parseArgs(tt.command, tt.args, io.Discard)
+			if tt.wantErr != nil {
+				if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cmd.name != tt.command {
+				t.Errorf("command = %q, want %q", cmd.name, tt.command)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestCommandsHaveUniqueFlags(t *testing.T) {
+	// Registering a flag twice on one flag set panics
+	for _, cmd := range commands {
+		t.Run(cmd.name, func(t *testing.T) {
+			if _, _, err := parseArgs(cmd.name, nil, io.Discard); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLogOutput(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    *os.File
+	}{
+		{"ingest", nil, os.Stdout},
+		{"ingest", []string{"-summary", "-"}, os.Stderr},
+		{"ingest", []string{"-summary", "summary.json"}, os.Stdout},
+		{"apikey create", []string{"-owner", "bi"}, os.Stderr},
+		{"stats", []string{"-hotel-id", "5"}, os.Stderr},
+		{"apikey list", nil, os.Stdout},
+	}
+
+	for _, tt := range tests {
+		cmd, cfg, err := parseArgs(tt.command, tt.args, io.Discard)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := logOutput(cmd, cfg); got != tt.want {
+			t.Errorf("%s %q logs to %s, want %s", tt.command, tt.args, got.(*os.File).Name(), tt.want.Name())
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// providersList prints the providers with their registered rating scales
+func (app *application) providersList(ctx context.Context) int {
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	providers, err := app.models.Provider.GetAll(ctx)
+	if err != nil {
+		app.logger.Error("error listing providers", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSCALE\tREVIEWS")
+	for _, provider := range providers {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\n",
+			provider.ID, provider.Name, strconv.FormatFloat(provider.RatingScale, 'f', -1, 64), provider.ReviewCount)
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing providers", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
+
+// providersScale registers -scale as the rating scale of -provider. Reviews
+// are renormalized by `ratings backfill`.
+func (app *application) providersScale(ctx context.Context) int {
+	if app.config.provider.name == "" {
+		app.logger.Error("-provider must be provided")
+		return exitFatal
+	}
+
+	v := validator.New()
+	if data.ValidateRatingScale(v, app.config.provider.scale); !v.Valid() {
+		app.logger.Error("invalid rating scale", slog.Any("errors", v.Errors))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	provider, err := app.models.Provider.SetRatingScale(ctx, app.config.provider.name, app.config.provider.scale)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.logger.Error("provider not found", slog.String("provider", app.config.provider.name))
+		default:
+			app.logger.Error("error setting rating scale", slog.String("error", err.Error()))
+		}
+		return exitFatal
+	}
+
+	app.logger.Info("rating scale set, run `ratings backfill` to renormalize existing reviews",
+		slog.Int("id", provider.ID),
+		slog.String("provider", provider.Name),
+		slog.Float64("scale", provider.RatingScale))
+	return exitSuccess
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// ratingsBackfill recomputes normalized_rating from the registered provider
+// scales in batches of -batch-size, refreshing the daily rollup of the days
+// that changed. Every batch commits on its own and reviews that are already
+// up to date are left alone, so a rerun resumes cheaply.
+func (app *application) ratingsBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	start := time.Now()
+	var afterID int64
+	var days int
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("ratings backfill interrupted", slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		lastID, changed, err := app.normalizeBatch(ctx, db, afterID)
+		if err != nil {
+			app.logger.Error("error normalizing ratings", slog.Int64("after_id", afterID), slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if lastID == 0 {
+			break
+		}
+
+		days += changed
+		afterID = lastID
+	}
+
+	app.logger.Info("ratings backfill complete",
+		slog.Int("days_refreshed", days),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
+
+// normalizeBatch normalizes one batch and refreshes its rollup days in a
+// transaction. It returns the last review id of the batch and the number of
+// hotel days refreshed.
+func (app *application) normalizeBatch(ctx context.Context, db *sql.DB, afterID int64) (int64, int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	lastID, keys, err := data.ReviewModel{DB: tx}.NormalizeRatings(ctx, afterID, app.config.backfill.batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := (data.DailyStatsModel{DB: tx}).Refresh(ctx, keys); err != nil {
+		return 0, 0, err
+	}
+
+	return lastID, len(keys), tx.Commit()
+}
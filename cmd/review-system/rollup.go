@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// rollupRebuild recomputes hotel_daily_stats from reviews, for -hotel-id
+// only when it is set
+func (app *application) rollupRebuild(ctx context.Context) int {
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	var hotelID *int64
+	if app.config.report.hotelID > 0 {
+		hotelID = &app.config.report.hotelID
+	}
+
+	start := time.Now()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		app.logger.Error("error starting transaction", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer tx.Rollback()
+
+	rows, err := data.DailyStatsModel{DB: tx}.Rebuild(ctx, hotelID)
+	if err != nil {
+		app.logger.Error("error rebuilding daily stats", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	if err := tx.Commit(); err != nil {
+		app.logger.Error("error committing daily stats", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	app.logger.Info("daily stats rebuilt", slog.Int64("rows", rows), slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
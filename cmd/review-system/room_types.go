@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/roomtypes"
+)
+
+// roomTypesBackfill recategorizes the room types that were not categorized
+// by the current rules, then links reviews imported before room types
+// existed to a room type by their room type name, in batches of
+// -batch-size. Every batch commits on its own, so an interrupted run can
+// simply be rerun.
+func (app *application) roomTypesBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	rules := roomtypes.Default()
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	start := time.Now()
+	var afterRoomTypeID, recategorized int
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("room types backfill interrupted", slog.Int("recategorized", recategorized), slog.Int("last_room_type_id", afterRoomTypeID))
+			return exitInterrupted
+		}
+
+		roomTypes, err := app.models.RoomTypes.ListOutdated(ctx, rules.Version, app.config.backfill.rescore, afterRoomTypeID, app.config.backfill.batchSize)
+		if err != nil {
+			app.logger.Error("error listing room types to categorize", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if len(roomTypes) == 0 {
+			break
+		}
+
+		categories := make(map[int]string, len(roomTypes))
+		for _, roomType := range roomTypes {
+			categories[roomType.ID] = rules.Categorize(roomType.Name)
+		}
+
+		changed, err := app.models.RoomTypes.SetCategories(ctx, rules.Version, categories)
+		if err != nil {
+			app.logger.Error("error storing room type categories", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		recategorized += changed
+		afterRoomTypeID = roomTypes[len(roomTypes)-1].ID
+	}
+
+	var afterID, linked int64
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("room types backfill interrupted", slog.Int64("linked", linked), slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		lastID, updated, err := app.linkRoomTypes(ctx, db, rules, afterID)
+		if err != nil {
+			app.logger.Error("error linking room types", slog.Int64("after_id", afterID), slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if lastID == 0 {
+			break
+		}
+
+		linked += updated
+		afterID = lastID
+	}
+
+	app.logger.Info("room types backfill complete",
+		slog.Int("recategorized", recategorized),
+		slog.Int64("linked", linked),
+		slog.String("version", rules.Version),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
+
+// linkRoomTypes links one batch of reviews to their room types, creating
+// the room types that don't exist yet, in a transaction. It returns the last
+// review id of the batch, 0 when there was nothing left, and the number of
+// reviews linked.
+func (app *application) linkRoomTypes(ctx context.Context, db *sql.DB, rules *roomtypes.Rules, afterID int64) (int64, int64, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	models := data.NewModels(tx)
+
+	reviews, err := models.Review.ListUnlinkedRoomTypes(ctx, afterID, app.config.backfill.batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(reviews) == 0 {
+		return 0, 0, nil
+	}
+
+	for _, review := range reviews {
+		roomType, err := models.RoomTypes.GetByName(review.HotelID, review.ProviderID, review.RoomTypeName)
+		if errors.Is(err, data.ErrRecordNotFound) {
+			roomType = &data.RoomType{
+				HotelID:      review.HotelID,
+				ProviderID:   review.ProviderID,
+				Name:         review.RoomTypeName,
+				Category:     rules.Categorize(review.RoomTypeName),
+				RulesVersion: rules.Version,
+			}
+			err = models.RoomTypes.CreateOrGet(roomType)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		review.RoomTypeID = roomType.ID
+	}
+
+	updated, err := models.Review.SetRoomTypes(ctx, reviews)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return reviews[len(reviews)-1].ID, updated, tx.Commit()
+}
+
+// roomTypesList prints the room types of -hotel-id with their categories
+func (app *application) roomTypesList(ctx context.Context) int {
+	if app.config.report.hotelID <= 0 {
+		app.logger.Error("-hotel-id must be provided")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	roomTypes, err := app.models.RoomTypes.GetForHotel(ctx, app.config.report.hotelID)
+	if err != nil {
+		app.logger.Error("error listing room types", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tPROVIDER ID\tPROVIDER ROOM TYPE ID\tNAME\tCATEGORY\tREVIEWS")
+	for _, roomType := range roomTypes {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%d\n",
+			roomType.ID, roomType.ProviderID, roomType.ProviderRoomTypeID, roomType.Name, roomType.Category, roomType.ReviewCount)
+	}
+
+	if err := tw.Flush(); err != nil {
+		app.logger.Error("error writing room types", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
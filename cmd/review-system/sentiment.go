@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/sentiment"
+)
+
+// sentimentBackfill scores the reviews that have no sentiment, or one from an
+// older lexicon version, in batches of -batch-size. Every batch commits on
+// its own, so an interrupted run resumes where it stopped when rerun.
+func (app *application) sentimentBackfill(ctx context.Context) int {
+	if app.config.backfill.batchSize <= 0 {
+		app.logger.Error("-batch-size must be greater than zero")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	start := time.Now()
+	var afterID, scored int64
+
+	for {
+		if ctx.Err() != nil {
+			app.logger.Warn("sentiment backfill interrupted", slog.Int64("scored", scored), slog.Int64("last_id", afterID))
+			return exitInterrupted
+		}
+
+		texts, err := app.models.Review.ListUnscored(ctx, sentiment.Version, app.config.backfill.rescore, afterID, app.config.backfill.batchSize)
+		if err != nil {
+			app.logger.Error("error listing reviews to score", slog.String("error", err.Error()))
+			return exitFatal
+		}
+		if len(texts) == 0 {
+			break
+		}
+
+		scores := make([]data.ReviewSentiment, len(texts))
+		for i, text := range texts {
+			scores[i] = data.ReviewSentiment{
+				ID:    text.ID,
+				Score: sentiment.ScoreReview(text.Positives, text.Negatives, text.Comments).Compound,
+			}
+		}
+
+		updated, err := app.models.Review.SetSentiment(ctx, sentiment.Version, scores)
+		if err != nil {
+			app.logger.Error("error storing sentiment", slog.String("error", err.Error()))
+			return exitFatal
+		}
+
+		scored += updated
+		afterID = texts[len(texts)-1].ID
+	}
+
+	app.logger.Info("sentiment backfill complete",
+		slog.Int64("scored", scored),
+		slog.Int("version", sentiment.Version),
+		slog.Duration("duration", time.Since(start)))
+	return exitSuccess
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mahesh-singh/review-system/internal/api"
+	"github.com/mahesh-singh/review-system/internal/aspects"
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+// serve runs the HTTP API until ctx is cancelled
+func (app *application) serve(ctx context.Context) int {
+	if app.config.limiter.enabled && (app.config.limiter.rps <= 0 || app.config.limiter.burst < 1 || app.config.limiter.dailyQuota < 0) {
+		app.logger.Error("-limiter-rps and -limiter-burst must be positive and -limiter-daily-quota must not be negative")
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	app.logger.Info("database connection tested")
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	// Reprocessing reads files with the same sources as the importer
+	sources, err := app.loadSources()
+	if err != nil {
+		app.logger.Error("error loading sources", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	resolveFile, err := app.fileResolver(sources)
+	if err != nil {
+		app.logger.Error("error configuring sources", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	anomalies, err := app.anomalyOptions()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	dedupeOptions, err := app.dedupeOptions()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	dictionary, err := aspects.Load(app.config.aspectsPath)
+	if err != nil {
+		app.logger.Error("error loading aspect dictionary", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	processingConfig := jsonl_processing.DefaultProcessingConfig()
+	processingConfig.ShutdownTimeout = app.config.shutdownTimeout
+	processingConfig.Aspects = dictionary
+	processingConfig.Anomalies = anomalies
+	processingConfig.DetectAnomalies = app.config.anomalies.detect
+	processingConfig.Dedupe = dedupeOptions
+	processingConfig.ClusterDuplicates = app.config.dedupe.cluster
+
+	processor := jsonl_processing.NewJSONLProcessingService(db, processingConfig, app.logger)
+
+	srv := api.New(api.Config{
+		Port:            app.config.port,
+		Env:             app.config.env,
+		ShutdownTimeout: app.config.shutdownTimeout,
+		GradeScales:     app.config.gradeScales,
+		CacheBytes:      app.config.cacheSizeMB << 20,
+		RateLimit: api.RateLimitConfig{
+			Enabled:    app.config.limiter.enabled,
+			RPS:        app.config.limiter.rps,
+			Burst:      app.config.limiter.burst,
+			DailyQuota: app.config.limiter.dailyQuota,
+			Shared:     app.config.limiter.shared,
+		},
+	}, app.logger, app.models, processor, resolveFile)
+
+	if err := srv.Serve(ctx); err != nil {
+		app.logger.Error("server error", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
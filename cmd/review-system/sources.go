@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mahesh-singh/review-system/internal/s3"
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+// sourceConfig is one bucket/prefix to ingest from, as listed in the
+// -sources file. Empty connection settings fall back to the command-line
+// AWS flags.
+type sourceConfig struct {
+	Name       string `json:"name"`
+	Bucket     string `json:"bucket"`
+	Prefix     string `json:"prefix"`
+	Region     string `json:"region"`
+	Profile    string `json:"profile"`
+	RoleARN    string `json:"role_arn"`
+	ExternalID string `json:"external_id"`
+	Endpoint   string `json:"endpoint"`
+	PathStyle  *bool  `json:"path_style"`
+	Platform   string `json:"platform"` // Overrides the platform field of every record
+}
+
+// loadSources reads the -sources file, or builds a single source from the
+// command-line flags when no file is given
+func (app *application) loadSources() ([]sourceConfig, error) {
+	if app.config.sourcesPath == "" {
+		return []sourceConfig{{
+			Name:   "default",
+			Bucket: app.config.aws.s3bucket,
+		}}, nil
+	}
+
+	js, err := os.ReadFile(app.config.sourcesPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sources file: %w", err)
+	}
+
+	var sources []sourceConfig
+	if err := json.Unmarshal(js, &sources); err != nil {
+		return nil, fmt.Errorf("error parsing sources file: %w", err)
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("sources file %s has no sources", app.config.sourcesPath)
+	}
+
+	names := make(map[string]bool, len(sources))
+	for i, source := range sources {
+		if source.Bucket == "" {
+			return nil, fmt.Errorf("source %d: bucket must be provided", i)
+		}
+		if source.Name == "" {
+			sources[i].Name = source.Bucket + "/" + source.Prefix
+		}
+		if names[sources[i].Name] {
+			return nil, fmt.Errorf("source %q is listed more than once", sources[i].Name)
+		}
+		names[sources[i].Name] = true
+	}
+
+	return sources, nil
+}
+
+// s3Config merges a source's settings over the command-line AWS flags
+func (app *application) s3Config(source sourceConfig) s3.Config {
+	cfg := s3.Config{
+		Region:          app.config.aws.region,
+		Endpoint:        app.config.aws.s3endpoint,
+		UsePathStyle:    app.config.aws.s3PathStyle,
+		AccessKey:       app.config.aws.accessKey,
+		SecretKey:       app.config.aws.secretKey,
+		SessionToken:    app.config.aws.sessionToken,
+		Profile:         app.config.aws.profile,
+		RoleARN:         app.config.aws.roleARN,
+		ExternalID:      app.config.aws.externalID,
+		RoleSessionName: app.config.aws.roleSessionName,
+	}
+
+	if source.Region != "" {
+		cfg.Region = source.Region
+	}
+	if source.Endpoint != "" {
+		cfg.Endpoint = source.Endpoint
+	}
+	if source.PathStyle != nil {
+		cfg.UsePathStyle = *source.PathStyle
+	}
+	// A source-specific profile replaces the shared static keys
+	if source.Profile != "" {
+		cfg.Profile = source.Profile
+		cfg.AccessKey, cfg.SecretKey, cfg.SessionToken = "", "", ""
+	}
+	if source.RoleARN != "" {
+		cfg.RoleARN = source.RoleARN
+		cfg.ExternalID = source.ExternalID
+	}
+
+	return cfg
+}
+
+// listSources lists the files of every source into a single work list. A
+// file reachable through two sources is only processed once.
+func (app *application) listSources(ctx context.Context, sources []sourceConfig) ([]jsonl_processing.FileToProcess, error) {
+	files := make([]jsonl_processing.FileToProcess, 0)
+	seen := make(map[string]bool)
+
+	for _, source := range sources {
+		s3client, err := s3.NewClient(app.s3Config(source))
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source.Name, err)
+		}
+
+		sourceFiles, err := s3client.ListFiles(ctx, source.Bucket, source.Prefix)
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source.Name, err)
+		}
+
+		reader := s3.NewS3FileReader(s3client)
+		for _, f := range sourceFiles {
+			if seen[f.S3Path] {
+				continue
+			}
+			seen[f.S3Path] = true
+
+			files = append(files, jsonl_processing.FileToProcess{
+				Filename: f.Key,
+				S3Path:   f.S3Path,
+				Source:   source.Name,
+				Platform: source.Platform,
+				Reader:   reader,
+			})
+		}
+
+		app.logger.Info("listed source",
+			slog.String("source", source.Name),
+			slog.Int("files", len(sourceFiles)))
+	}
+
+	return files, nil
+}
+
+// fileResolver returns a resolver that maps an S3 path to the source whose
+// bucket and longest prefix match it, with that source's reader and
+// platform override
+func (app *application) fileResolver(sources []sourceConfig) (func(s3Path string) (jsonl_processing.FileToProcess, error), error) {
+	readers := make([]s3.FileReader, len(sources))
+	for i, source := range sources {
+		s3client, err := s3.NewClient(app.s3Config(source))
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source.Name, err)
+		}
+		readers[i] = s3.NewS3FileReader(s3client)
+	}
+
+	return func(s3Path string) (jsonl_processing.FileToProcess, error) {
+		bucket, key, err := s3.ParsePath(s3Path)
+		if err != nil {
+			return jsonl_processing.FileToProcess{}, err
+		}
+
+		match := -1
+		for i, source := range sources {
+			if source.Bucket != bucket || !strings.HasPrefix(key, source.Prefix) {
+				continue
+			}
+			if match == -1 || len(source.Prefix) > len(sources[match].Prefix) {
+				match = i
+			}
+		}
+
+		if match == -1 {
+			return jsonl_processing.FileToProcess{}, fmt.Errorf("no configured source matches %s", s3Path)
+		}
+
+		return jsonl_processing.FileToProcess{
+			Filename: key,
+			S3Path:   s3Path,
+			Source:   sources[match].Name,
+			Platform: sources[match].Platform,
+			Reader:   readers[match],
+		}, nil
+	}, nil
+}
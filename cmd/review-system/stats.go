@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// stats prints the review statistics of -hotel-id as JSON
+func (app *application) stats(ctx context.Context) int {
+	if app.config.report.hotelID <= 0 {
+		app.logger.Error("-hotel-id must be provided")
+		return exitFatal
+	}
+
+	window, err := app.dateWindow()
+	if err != nil {
+		app.logger.Error(err.Error())
+		return exitFatal
+	}
+
+	v := validator.New()
+	if data.ValidateLanguage(v, "language", app.config.report.language); !v.Valid() {
+		app.logger.Error("invalid language", slog.Any("errors", v.Errors))
+		return exitFatal
+	}
+
+	db, err := openDB(&app.config)
+	if err != nil {
+		app.logger.Error("error in connecting database", slog.String("error", err.Error()))
+		return exitFatal
+	}
+	defer db.Close()
+
+	app.models = data.NewModels(db)
+
+	stats, err := app.models.Analytics.HotelStats(ctx, app.config.report.hotelID, window, app.config.report.language)
+	if err != nil {
+		app.logger.Error("error computing hotel stats", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	if err := printJSON(stats); err != nil {
+		app.logger.Error("error writing stats", slog.String("error", err.Error()))
+		return exitFatal
+	}
+
+	return exitSuccess
+}
+
+// dateWindow parses the -from/-to flags, which take YYYY-MM-DD dates
+func (app *application) dateWindow() (data.DateWindow, error) {
+	var window data.DateWindow
+
+	if app.config.report.from != "" {
+		from, err := time.Parse(time.DateOnly, app.config.report.from)
+		if err != nil {
+			return window, fmt.Errorf("-from must be a YYYY-MM-DD date")
+		}
+		window.From = &from
+	}
+
+	if app.config.report.to != "" {
+		to, err := time.Parse(time.DateOnly, app.config.report.to)
+		if err != nil {
+			return window, fmt.Errorf("-to must be a YYYY-MM-DD date")
+		}
+		window.To = &to
+	}
+
+	if window.From != nil && window.To != nil && !window.From.Before(*window.To) {
+		return window, errors.New("-from must be before -to")
+	}
+
+	return window, nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v any) error {
+	js, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	js = append(js, '\n')
+
+	_, err = os.Stdout.Write(js)
+	return err
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+// Exit codes let schedulers branch on the outcome of a run
+const (
+	exitSuccess     = 0 // every file succeeded or was already processed
+	exitFatal       = 1 // setup failed before any file was processed
+	exitPartial     = 2 // some records or files failed, within the threshold
+	exitFailed      = 3 // failures exceeded -fail-threshold
+	exitInterrupted = 4 // stopped by SIGINT/SIGTERM, rerun to resume
+)
+
+const (
+	outcomeSuccess     = "success"
+	outcomeFatal       = "fatal"
+	outcomePartial     = "partial"
+	outcomeFailed      = "failed"
+	outcomeInterrupted = "interrupted"
+)
+
+// setOutcome classifies the run, records it on the summary and returns the
+// matching exit code
+func (app *application) setOutcome(summary *jsonl_processing.RunSummary, interrupted bool) int {
+	var failedFilesPct, errorRecordsPct float64
+	if summary.TotalFiles > 0 {
+		failedFilesPct = float64(summary.FailedFiles) / float64(summary.TotalFiles) * 100
+	}
+	if summary.TotalRecords > 0 {
+		errorRecordsPct = float64(summary.ErrorRecords) / float64(summary.TotalRecords) * 100
+	}
+
+	switch {
+	case interrupted:
+		summary.Outcome = outcomeInterrupted
+		return exitInterrupted
+	case failedFilesPct > app.config.failThreshold || errorRecordsPct > app.config.failThreshold:
+		summary.Outcome = outcomeFailed
+		return exitFailed
+	case summary.FailedFiles > 0 || summary.PartialFiles > 0 || summary.ErrorRecords > 0:
+		summary.Outcome = outcomePartial
+		return exitPartial
+	default:
+		summary.Outcome = outcomeSuccess
+		return exitSuccess
+	}
+}
+
+// fatal logs a setup error, writes a fatal summary and returns exitFatal
+func (app *application) fatal(startedAt time.Time, msg string, err error) int {
+	app.logger.Error(msg, slog.String("error", err.Error()))
+
+	summary := jsonl_processing.NewRunSummary(startedAt, nil)
+	summary.Outcome = outcomeFatal
+	summary.Error = err.Error()
+
+	if err := app.writeSummary(summary); err != nil {
+		app.logger.Error("error writing run summary", slog.String("error", err.Error()))
+	}
+
+	return exitFatal
+}
+
+// writeSummary writes the summary as JSON to -summary, if set
+func (app *application) writeSummary(summary *jsonl_processing.RunSummary) error {
+	if app.config.summaryPath == "" {
+		return nil
+	}
+
+	js, err := json.MarshalIndent(summary, "", "\t")
+	if err != nil {
+		return err
+	}
+	js = append(js, '\n')
+
+	if app.config.summaryPath == "-" {
+		_, err = os.Stdout.Write(js)
+		return err
+	}
+
+	return os.WriteFile(app.config.summaryPath, js, 0644)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+func TestSetOutcome(t *testing.T) {
+	tests := []struct {
+		name        string
+		totals      jsonl_processing.RunTotals
+		interrupted bool
+		wantCode    int
+		wantOutcome string
+	}{
+		{"no files", jsonl_processing.RunTotals{}, false, exitSuccess, outcomeSuccess},
+		{
+			name:     "all succeeded or skipped",
+			totals:   jsonl_processing.RunTotals{TotalFiles: 3, SuccessFiles: 2, SkippedFiles: 1, TotalRecords: 100, SuccessRecords: 100},
+			wantCode: exitSuccess, wantOutcome: outcomeSuccess,
+		},
+		{
+			name:     "partial file",
+			totals:   jsonl_processing.RunTotals{TotalFiles: 20, SuccessFiles: 19, PartialFiles: 1, TotalRecords: 100, SuccessRecords: 99, ErrorRecords: 1},
+			wantCode: exitPartial, wantOutcome: outcomePartial,
+		},
+		{
+			name:     "failed files at the threshold",
+			totals:   jsonl_processing.RunTotals{TotalFiles: 10, SuccessFiles: 9, FailedFiles: 1},
+			wantCode: exitPartial, wantOutcome: outcomePartial,
+		},
+		{
+			name:     "failed files above the threshold",
+			totals:   jsonl_processing.RunTotals{TotalFiles: 5, SuccessFiles: 4, FailedFiles: 1},
+			wantCode: exitFailed, wantOutcome: outcomeFailed,
+		},
+		{
+			name:     "error records above the threshold",
+			totals:   jsonl_processing.RunTotals{TotalFiles: 20, SuccessFiles: 19, PartialFiles: 1, TotalRecords: 100, SuccessRecords: 89, ErrorRecords: 11},
+			wantCode: exitFailed, wantOutcome: outcomeFailed,
+		},
+		{
+			name:        "interrupted wins over failures",
+			totals:      jsonl_processing.RunTotals{TotalFiles: 2, FailedFiles: 1, InterruptedFiles: 1},
+			interrupted: true,
+			wantCode:    exitInterrupted, wantOutcome: outcomeInterrupted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &application{config: appConfig{failThreshold: 10}}
+			summary := &jsonl_processing.RunSummary{RunTotals: tt.totals}
+
+			if code := app.setOutcome(summary, tt.interrupted); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			if summary.Outcome != tt.wantOutcome {
+				t.Errorf("outcome = %q, want %q", summary.Outcome, tt.wantOutcome)
+			}
+		})
+	}
+}
+
+// The exit codes are a contract with the schedulers that run the importer
+func TestExitCodes(t *testing.T) {
+	codes := map[string]int{
+		outcomeSuccess:     exitSuccess,
+		outcomeFatal:       exitFatal,
+		outcomePartial:     exitPartial,
+		outcomeFailed:      exitFailed,
+		outcomeInterrupted: exitInterrupted,
+	}
+	want := map[string]int{"success": 0, "fatal": 1, "partial": 2, "failed": 3, "interrupted": 4}
+
+	for outcome, code := range want {
+		if codes[outcome] != code {
+			t.Errorf("%s exits with %d, want %d", outcome, codes[outcome], code)
+		}
+	}
+}
+
+func TestFatal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	app := &application{
+		config: appConfig{summaryPath: path},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	if code := app.fatal(time.Now(), "error in connecting database", errors.New("connection refused")); code != exitFatal {
+		t.Errorf("exit code = %d, want %d", code, exitFatal)
+	}
+
+	js, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary jsonl_processing.RunSummary
+	if err := json.Unmarshal(js, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Outcome != outcomeFatal || summary.Error != "connection refused" {
+		t.Errorf("summary outcome = %q with error %q, want %q with the error", summary.Outcome, summary.Error, outcomeFatal)
+	}
+}
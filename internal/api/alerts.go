@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) listAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.AlertFilter
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	if hotelID := s.readOptionalInt(qs, "hotel_id", v); hotelID != nil {
+		id := int64(*hotelID)
+		input.HotelID = &id
+	}
+	input.Kind = s.readString(qs, "kind", "")
+	input.Severity = s.readString(qs, "severity", "")
+	input.Status = s.readString(qs, "status", "")
+
+	input.Filters.Page = s.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = s.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = s.readString(qs, "sort", "-detected_at")
+	input.Filters.SortSafelist = []string{"detected_at", "updated_at", "-detected_at", "-updated_at"}
+
+	if data.ValidateAlertFilter(v, input); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	alerts, metadata, err := s.models.Alerts.GetAll(r.Context(), input)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"alerts": alerts, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) showHotelAspectsHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	window := s.readDateWindow(r, v)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	summaries, err := s.models.Analytics.HotelAspects(r.Context(), hotel.HotelID, window)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"aspects": summaries}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
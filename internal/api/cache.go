@@ -0,0 +1,238 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// responseCache is an LRU cache of hotel responses bounded by the total
+// size of the cached bodies. Entries record the hotel version they were
+// built from, so a newer import makes them stale even when it ran in
+// another process.
+type responseCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	size     int
+	order    *list.List // Most recently used at the front
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	hotelID int64
+	version time.Time
+	body    []byte
+}
+
+func newResponseCache(maxBytes int) *responseCache {
+	return &responseCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the body cached for key if it was built from version
+func (c *responseCache) get(key string, version time.Time) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !entry.version.Equal(version) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.body, true
+}
+
+func (c *responseCache) put(key string, hotelID int64, version time.Time, body []byte) {
+	if len(body) > c.maxBytes/8 {
+		return // Don't let one response flush the cache
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, hotelID: hotelID, version: version, body: body})
+	c.size += len(body)
+
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// evictHotels drops every response of the given hotels
+func (c *responseCache) evictHotels(hotelIDs []int64) {
+	evict := make(map[int64]bool, len(hotelIDs))
+	for _, hotelID := range hotelIDs {
+		evict[hotelID] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if evict[elem.Value.(*cacheEntry).hotelID] {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+func (c *responseCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= len(entry.body)
+}
+
+// bodyRecorder passes a response through while keeping a copy of a 200
+// body. Validators are only added to 200 responses.
+type bodyRecorder struct {
+	http.ResponseWriter
+	status     int
+	body       bytes.Buffer
+	validators http.Header
+}
+
+func (rec *bodyRecorder) WriteHeader(status int) {
+	rec.status = status
+	if status == http.StatusOK {
+		for key, value := range rec.validators {
+			rec.Header()[key] = value
+		}
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *bodyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if rec.status == http.StatusOK {
+		rec.body.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *bodyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// cacheHotelResponse adds ETag and Last-Modified headers to a response
+// about {hotel_id}, answers matching conditional requests with 304 and
+// serves repeated requests from the response cache. The hotel's version
+// comes from the latest updated_at of its data, which the importer bumps
+// on every commit.
+func (s *Server) cacheHotelResponse(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hotelID, err := s.readHotelIDParam(r)
+		if err != nil {
+			next(w, r)
+			return
+		}
+
+		version, err := s.models.Hotel.GetVersion(r.Context(), hotelID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				next(w, r) // Responds with 404
+			default:
+				s.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		validators := http.Header{}
+		validators.Set("ETag", hotelETag(hotelID, version))
+		validators.Set("Last-Modified", version.UTC().Format(http.TimeFormat))
+		validators.Set("Cache-Control", "private, no-cache")
+
+		if notModified(r, validators.Get("ETag"), version) {
+			for key, value := range validators {
+				w.Header()[key] = value
+			}
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if s.cache == nil {
+			next(&bodyRecorder{ResponseWriter: w, validators: validators}, r)
+			return
+		}
+
+		key := r.URL.Path + "?" + r.URL.Query().Encode()
+
+		if body, ok := s.cache.get(key, version); ok {
+			for key, value := range validators {
+				w.Header()[key] = value
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			w.Write(body)
+			return
+		}
+
+		validators.Set("X-Cache", "MISS")
+
+		rec := &bodyRecorder{ResponseWriter: w, validators: validators}
+		next(rec, r)
+
+		if rec.status == http.StatusOK {
+			s.cache.put(key, hotelID, version, rec.body.Bytes())
+		}
+	}
+}
+
+// hotelETag is weak as it only identifies the data a response was built
+// from. The API version is included so a release that changes response
+// shapes invalidates clients' copies.
+func hotelETag(hotelID int64, modified time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d", version, hotelID, modified.UnixNano())))
+	return `W/"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when there is
+// no If-None-Match, as RFC 9110 requires
+func notModified(r *http.Request, etag string, version time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err == nil && !version.Truncate(time.Second).After(t) {
+			return true
+		}
+	}
+
+	return false
+}
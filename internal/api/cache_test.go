@@ -0,0 +1,290 @@
+package api
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/data/datatest"
+)
+
+func TestResponseCache(t *testing.T) {
+	v1 := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	v2 := v1.Add(time.Minute)
+	body := func(s string) []byte { return []byte(fmt.Sprintf("%-10s", s)) } // 10 bytes
+
+	t.Run("hit and miss", func(t *testing.T) {
+		c := newResponseCache(100)
+		c.put("a", 1, v1, body("a"))
+
+		if got, ok := c.get("a", v1); !ok || string(got) != string(body("a")) {
+			t.Errorf("get(a) = %q, %v, want the cached body", got, ok)
+		}
+		if _, ok := c.get("b", v1); ok {
+			t.Error("get(b) hit an entry that was never put")
+		}
+	})
+
+	t.Run("newer version is a miss", func(t *testing.T) {
+		c := newResponseCache(100)
+		c.put("a", 1, v1, body("a"))
+
+		if _, ok := c.get("a", v2); ok {
+			t.Error("get with a newer version hit the stale entry")
+		}
+		// The stale entry is dropped, so even its own version misses now
+		if _, ok := c.get("a", v1); ok || c.size != 0 {
+			t.Errorf("stale entry kept, size = %d", c.size)
+		}
+	})
+
+	t.Run("least recently used is evicted", func(t *testing.T) {
+		c := newResponseCache(80)
+		for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+			c.put(key, 1, v1, body(key))
+		}
+		c.get("a", v1) // a is now the most recently used
+		c.put("i", 1, v1, body("i"))
+
+		if _, ok := c.get("b", v1); ok {
+			t.Error("b, the least recently used entry, was not evicted")
+		}
+		for _, key := range []string{"a", "c", "i"} {
+			if _, ok := c.get(key, v1); !ok {
+				t.Errorf("%s was evicted", key)
+			}
+		}
+		if c.size != 80 {
+			t.Errorf("size = %d, want 80", c.size)
+		}
+	})
+
+	t.Run("replacing a key", func(t *testing.T) {
+		c := newResponseCache(100)
+		c.put("a", 1, v1, body("a"))
+		c.put("a", 1, v2, []byte("short"))
+
+		if got, ok := c.get("a", v2); !ok || string(got) != "short" {
+			t.Errorf("get(a) = %q, %v, want the new body", got, ok)
+		}
+		if c.size != 5 || c.order.Len() != 1 {
+			t.Errorf("size = %d with %d entries, want 5 with 1", c.size, c.order.Len())
+		}
+	})
+
+	t.Run("oversized body is not cached", func(t *testing.T) {
+		c := newResponseCache(80)
+		c.put("a", 1, v1, body("a"))
+		c.put("big", 1, v1, make([]byte, 11))
+
+		if _, ok := c.get("big", v1); ok {
+			t.Error("a body over an eighth of the cache was cached")
+		}
+		if _, ok := c.get("a", v1); !ok {
+			t.Error("the oversized body evicted other entries")
+		}
+	})
+
+	t.Run("evict hotels", func(t *testing.T) {
+		c := newResponseCache(100)
+		c.put("a", 1, v1, body("a"))
+		c.put("b", 2, v1, body("b"))
+		c.put("c", 3, v1, body("c"))
+		c.evictHotels([]int64{1, 3})
+
+		for key, want := range map[string]bool{"a": false, "b": true, "c": false} {
+			if _, ok := c.get(key, v1); ok != want {
+				t.Errorf("get(%s) = %v, want %v", key, ok, want)
+			}
+		}
+		if c.size != 10 {
+			t.Errorf("size = %d, want 10", c.size)
+		}
+	})
+}
+
+func TestNotModified(t *testing.T) {
+	version := time.Date(2024, 5, 10, 12, 0, 0, 500_000_000, time.UTC)
+	etag := hotelETag(1, version)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"no conditions", nil, false},
+		{"matching etag", map[string]string{"If-None-Match": etag}, true},
+		{"strong form of the etag", map[string]string{"If-None-Match": strings.TrimPrefix(etag, "W/")}, true},
+		{"etag in a list", map[string]string{"If-None-Match": `W/"other", ` + etag}, true},
+		{"any etag", map[string]string{"If-None-Match": "*"}, true},
+		{"other etag", map[string]string{"If-None-Match": `W/"other"`}, false},
+		{"modified since", map[string]string{"If-Modified-Since": version.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"not modified since", map[string]string{"If-Modified-Since": version.Format(http.TimeFormat)}, true},
+		{"later date", map[string]string{"If-Modified-Since": version.Add(time.Hour).Format(http.TimeFormat)}, true},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{
+			name: "etag takes precedence over date",
+			headers: map[string]string{
+				"If-None-Match":     `W/"other"`,
+				"If-Modified-Since": version.Add(time.Hour).Format(http.TimeFormat),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/hotels/1", nil)
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+			if got := notModified(r, etag, version); got != tt.want {
+				t.Errorf("notModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacheHotelResponse(t *testing.T) {
+	version := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	newer := version.Add(time.Hour)
+	etag := hotelETag(5, version)
+
+	type request struct {
+		url       string
+		hotelID   string
+		headers   map[string]string
+		version   time.Time // Zero for an unknown hotel
+		status    int       // Returned by the handler
+		wantCode  int
+		wantCache string // X-Cache, empty when not set
+		wantCalls int    // Handler calls so far
+	}
+
+	tests := []struct {
+		name       string
+		cacheBytes int
+		requests   []request
+	}{
+		{
+			name:       "cached",
+			cacheBytes: 1 << 20,
+			requests: []request{
+				{url: "/v1/hotels/5/reviews?sort=rating", version: version, wantCode: 200, wantCache: "MISS", wantCalls: 1},
+				{url: "/v1/hotels/5/reviews?sort=rating", version: version, wantCode: 200, wantCache: "HIT", wantCalls: 1},
+				{url: "/v1/hotels/5/reviews?sort=date", version: version, wantCode: 200, wantCache: "MISS", wantCalls: 2},
+				{url: "/v1/hotels/5/reviews?sort=rating", headers: map[string]string{"If-None-Match": etag}, version: version, wantCode: 304, wantCalls: 2},
+				{url: "/v1/hotels/5/reviews?sort=rating", headers: map[string]string{"If-Modified-Since": version.Format(http.TimeFormat)}, version: version, wantCode: 304, wantCalls: 2},
+				// An import in another process bumps the version
+				{url: "/v1/hotels/5/reviews?sort=rating", headers: map[string]string{"If-None-Match": etag}, version: newer, wantCode: 200, wantCache: "MISS", wantCalls: 3},
+				{url: "/v1/hotels/5/reviews?sort=rating", version: newer, wantCode: 200, wantCache: "HIT", wantCalls: 3},
+			},
+		},
+		{
+			name:       "errors are not cached",
+			cacheBytes: 1 << 20,
+			requests: []request{
+				{url: "/v1/hotels/5/reviews", version: version, status: 500, wantCode: 500, wantCalls: 1},
+				{url: "/v1/hotels/5/reviews", version: version, wantCode: 200, wantCache: "MISS", wantCalls: 2},
+			},
+		},
+		{
+			name:       "unknown hotel",
+			cacheBytes: 1 << 20,
+			requests: []request{
+				{url: "/v1/hotels/9/reviews", hotelID: "9", status: 404, wantCode: 404, wantCalls: 1},
+				{url: "/v1/hotels/x/reviews", hotelID: "x", status: 404, wantCode: 404, wantCalls: 2},
+			},
+		},
+		{
+			name: "cache disabled",
+			requests: []request{
+				{url: "/v1/hotels/5/reviews", version: version, wantCode: 200, wantCalls: 1},
+				{url: "/v1/hotels/5/reviews", version: version, wantCode: 200, wantCalls: 2},
+				{url: "/v1/hotels/5/reviews", headers: map[string]string{"If-None-Match": etag}, version: version, wantCode: 304, wantCalls: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current time.Time
+			db, _ := datatest.Open(func(query string, args []driver.Value) datatest.Result {
+				if !strings.Contains(query, "SELECT greatest") || current.IsZero() {
+					return datatest.Result{}
+				}
+				return datatest.Result{Columns: []string{"greatest"}, Rows: [][]driver.Value{{current}}}
+			})
+			defer db.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			s := New(Config{CacheBytes: tt.cacheBytes}, logger, data.NewModels(db), nil, nil)
+
+			calls := 0
+			status := 0
+			handler := s.cacheHotelResponse(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if status != 0 {
+					w.WriteHeader(status)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"call":%d}`, calls)
+			})
+
+			var cachedBody string
+			for i, req := range tt.requests {
+				current, status = req.version, req.status
+				hotelID := req.hotelID
+				if hotelID == "" {
+					hotelID = "5"
+				}
+
+				r := httptest.NewRequest(http.MethodGet, req.url, nil)
+				r.SetPathValue("hotel_id", hotelID)
+				for key, value := range req.headers {
+					r.Header.Set(key, value)
+				}
+				w := httptest.NewRecorder()
+				handler(w, r)
+
+				if w.Code != req.wantCode || calls != req.wantCalls {
+					t.Fatalf("request %d: status = %d after %d handler calls, want %d after %d", i+1, w.Code, calls, req.wantCode, req.wantCalls)
+				}
+				if got := w.Header().Get("X-Cache"); got != req.wantCache {
+					t.Errorf("request %d: X-Cache = %q, want %q", i+1, got, req.wantCache)
+				}
+
+				switch w.Code {
+				case http.StatusOK, http.StatusNotModified:
+					if got, want := w.Header().Get("ETag"), hotelETag(5, req.version); got != want {
+						t.Errorf("request %d: ETag = %q, want %q", i+1, got, want)
+					}
+					if got, want := w.Header().Get("Last-Modified"), req.version.Format(http.TimeFormat); got != want {
+						t.Errorf("request %d: Last-Modified = %q, want %q", i+1, got, want)
+					}
+				default:
+					if w.Header().Get("ETag") != "" {
+						t.Errorf("request %d: ETag set on a %d response", i+1, w.Code)
+					}
+				}
+
+				switch req.wantCache {
+				case "MISS":
+					cachedBody = w.Body.String()
+				case "HIT":
+					if w.Body.String() != cachedBody {
+						t.Errorf("request %d: body = %q, want the cached %q", i+1, w.Body, cachedBody)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+type contextKey string
+
+const apiKeyContextKey = contextKey("apiKey")
+
+// contextSetAPIKey returns a copy of the request with the authenticated key
+func (s *Server) contextSetAPIKey(r *http.Request, key *data.APIKey) *http.Request {
+	ctx := context.WithValue(r.Context(), apiKeyContextKey, key)
+	return r.WithContext(ctx)
+}
+
+// contextGetAPIKey returns the authenticated key, or nil for anonymous requests
+func (s *Server) contextGetAPIKey(r *http.Request) *data.APIKey {
+	key, _ := r.Context().Value(apiKeyContextKey).(*data.APIKey)
+	return key
+}
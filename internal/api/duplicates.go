@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) showDuplicationReportHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+
+	var hotelID *int64
+	if id := s.readOptionalInt(r.URL.Query(), "hotel_id", v); id != nil {
+		value := int64(*id)
+		hotelID = &value
+	}
+
+	window := s.readDateWindow(r, v)
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	report, err := s.models.Analytics.DuplicationByProvider(r.Context(), hotelID, window)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"providers": report}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s *Server) logError(r *http.Request, err error) {
+	s.logger.Error(err.Error(),
+		slog.String("request_method", r.Method),
+		slog.String("request_url", r.URL.String()))
+}
+
+func (s *Server) errorResponse(w http.ResponseWriter, r *http.Request, status int, message any) {
+	env := envelope{"error": message}
+
+	err := s.writeJSON(w, status, env, nil)
+	if err != nil {
+		s.logError(r, err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.logError(r, err)
+
+	message := "the server encountered a problem and could not process your request"
+	s.errorResponse(w, r, http.StatusInternalServerError, message)
+}
+
+func (s *Server) notFoundResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the requested resource could not be found"
+	s.errorResponse(w, r, http.StatusNotFound, message)
+}
+
+func (s *Server) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
+	s.errorResponse(w, r, http.StatusBadRequest, err.Error())
+}
+
+func (s *Server) failedValidationResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
+	s.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
+}
+
+func (s *Server) conflictResponse(w http.ResponseWriter, r *http.Request, message string) {
+	s.errorResponse(w, r, http.StatusConflict, message)
+}
+
+func (s *Server) invalidAPIKeyResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "invalid, expired or revoked api key"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) authenticationRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+
+	message := "you must provide an api key to access this resource"
+	s.errorResponse(w, r, http.StatusUnauthorized, message)
+}
+
+func (s *Server) notPermittedResponse(w http.ResponseWriter, r *http.Request, scope string) {
+	message := fmt.Sprintf("your api key does not have the %s scope required for this resource", scope)
+	s.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+func (s *Server) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request) {
+	message := "rate limit exceeded"
+	s.errorResponse(w, r, http.StatusTooManyRequests, message)
+}
+
+func (s *Server) quotaExceededResponse(w http.ResponseWriter, r *http.Request) {
+	message := "daily quota exceeded"
+	s.errorResponse(w, r, http.StatusTooManyRequests, message)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) compareHotelGradesHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	opts := data.GradeComparisonOptions{
+		Threshold:       10,
+		Scales:          s.config.GradeScales,
+		PlatformAverage: true,
+	}
+
+	if threshold := s.readOptionalFloat(qs, "threshold", v); threshold != nil {
+		opts.Threshold = *threshold
+	}
+	if platformAverage := s.readOptionalBool(qs, "platform_average", v); platformAverage != nil {
+		opts.PlatformAverage = *platformAverage
+	}
+
+	v.Check(opts.Threshold >= 0 && opts.Threshold <= 100, "threshold", "must be between 0 and 100")
+
+	if !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	comparison, err := s.models.Analytics.CompareProviderGrades(r.Context(), hotel, opts)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"comparison": comparison}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+type envelope map[string]any
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, data envelope, headers http.Header) error {
+	js, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	js = append(js, '\n')
+
+	for key, value := range headers {
+		w.Header()[key] = value
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+
+	return nil
+}
+
+// readIDParam reads the {id} path value
+func (s *Server) readIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid id parameter")
+	}
+
+	return id, nil
+}
+
+// readHotelIDParam reads the {hotel_id} path value
+func (s *Server) readHotelIDParam(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("hotel_id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid hotel_id parameter")
+	}
+
+	return id, nil
+}
+
+// readString returns a string value from the query string, or the provided
+// default value if no matching key could be found
+func (s *Server) readString(qs url.Values, key string, defaultValue string) string {
+	str := qs.Get(key)
+
+	if str == "" {
+		return defaultValue
+	}
+
+	return str
+}
+
+// readInt reads a query string value and converts it to an integer. If the
+// value cannot be converted, the error is recorded in the Validator.
+func (s *Server) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
+	str := qs.Get(key)
+
+	if str == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return defaultValue
+	}
+
+	return i
+}
+
+// readOptionalInt reads an integer query string value, returning nil when the
+// key is absent
+func (s *Server) readOptionalInt(qs url.Values, key string, v *validator.Validator) *int {
+	str := qs.Get(key)
+	if str == "" {
+		return nil
+	}
+
+	i, err := strconv.Atoi(str)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return nil
+	}
+
+	return &i
+}
+
+// readOptionalFloat reads a numeric query string value, returning nil when the
+// key is absent
+func (s *Server) readOptionalFloat(qs url.Values, key string, v *validator.Validator) *float64 {
+	str := qs.Get(key)
+	if str == "" {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		v.AddError(key, "must be a number")
+		return nil
+	}
+
+	return &f
+}
+
+// readOptionalBool reads a boolean query string value, returning nil when the
+// key is absent
+func (s *Server) readOptionalBool(qs url.Values, key string, v *validator.Validator) *bool {
+	str := qs.Get(key)
+	if str == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(str)
+	if err != nil {
+		v.AddError(key, "must be true or false")
+		return nil
+	}
+
+	return &b
+}
+
+// readOptionalDate reads a YYYY-MM-DD or RFC 3339 query string value,
+// returning nil when the key is absent
+func (s *Server) readOptionalDate(qs url.Values, key string, v *validator.Validator) *time.Time {
+	str := qs.Get(key)
+	if str == "" {
+		return nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, str); err == nil {
+			return &t
+		}
+	}
+
+	v.AddError(key, "must be a date in YYYY-MM-DD or RFC 3339 format")
+	return nil
+}
+
+// background runs fn in a goroutine that Serve waits for on shutdown,
+// recovering any panic
+func (s *Server) background(fn func()) {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				s.logger.Error(fmt.Sprintf("%v", err))
+			}
+		}()
+
+		fn()
+	}()
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) listHotelsHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.HotelFilter
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Platform = s.readString(qs, "platform", "")
+	input.NamePrefix = s.readString(qs, "name", "")
+
+	input.Filters.Page = s.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = s.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = s.readString(qs, "sort", "hotel_id")
+	input.Filters.SortSafelist = []string{"hotel_id", "name", "updated_at", "-hotel_id", "-name", "-updated_at"}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	hotels, metadata, err := s.models.Hotel.GetAll(r.Context(), input)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"hotels": hotels, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) showHotelHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+	hotelID := hotel.HotelID
+
+	ratings, err := s.models.HotelProviderRating.GetForHotel(r.Context(), hotelID)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	summary, err := s.models.Review.GetSummary(r.Context(), hotelID)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	env := envelope{
+		"hotel":            hotel,
+		"provider_ratings": ratings,
+		"review_summary":   summary,
+	}
+
+	err = s.writeJSON(w, http.StatusOK, env, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// requireHotel reads {hotel_id} and loads the hotel, writing a 404 or 500
+// response and returning false when it can't
+func (s *Server) requireHotel(w http.ResponseWriter, r *http.Request) (*data.Hotel, bool) {
+	hotelID, err := s.readHotelIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	hotel, err := s.models.Hotel.Get(r.Context(), hotelID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return hotel, true
+}
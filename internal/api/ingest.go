@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) listProcessedFilesHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.ProcessedFileFilter
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Status = s.readString(qs, "status", "")
+	input.From = s.readOptionalDate(qs, "from", v)
+	input.To = s.readOptionalDate(qs, "to", v)
+
+	input.Filters.Page = s.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = s.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = s.readString(qs, "sort", "-processed_at")
+	input.Filters.SortSafelist = []string{"id", "processed_at", "errors_count", "-id", "-processed_at", "-errors_count"}
+
+	if input.Status != "" {
+		v.Check(validator.PermittedValue(input.Status,
+			data.FileStatusProcessing,
+			data.FileStatusSuccess,
+			data.FileStatusPartial,
+			data.FileStatusFailed,
+			data.FileStatusInterrupted,
+		), "status", "invalid status value")
+	}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	files, metadata, err := s.models.ProcessedFiles.GetAll(r.Context(), input)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"files": files, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// requireProcessedFile reads {id} and loads the processed file, writing a
+// 404 or 500 response and returning false when it can't
+func (s *Server) requireProcessedFile(w http.ResponseWriter, r *http.Request) (*data.ProcessedFile, bool) {
+	id, err := s.readIDParam(r)
+	if err != nil {
+		s.notFoundResponse(w, r)
+		return nil, false
+	}
+
+	file, err := s.models.ProcessedFiles.Get(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			s.notFoundResponse(w, r)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+
+	return file, true
+}
+
+func (s *Server) showProcessedFileHandler(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.requireProcessedFile(w, r)
+	if !ok {
+		return
+	}
+
+	err := s.writeJSON(w, http.StatusOK, envelope{"file": file}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+func (s *Server) listProcessedFileErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.requireProcessedFile(w, r)
+	if !ok {
+		return
+	}
+
+	var input data.ProcessingErrorFilter
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Category = s.readString(qs, "category", "")
+
+	input.Filters.Page = s.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = s.readInt(qs, "page_size", 50, v)
+	input.Filters.Sort = "line_number"
+	input.Filters.SortSafelist = []string{"line_number"}
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	processingErrors, metadata, err := s.models.ProcessingErrors.GetAllForFile(r.Context(), file.ID, input)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"errors": processingErrors, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
+
+// reprocessFileHandler starts a background re-import of the file's S3 path
+// and responds with 202 Accepted. The run resumes the path's most recent
+// Interrupted record from its last committed line if there is one, and
+// otherwise gets its own processed file record. A record left Processing
+// by a run that crashed is marked Interrupted and resumed once it is
+// StaleProcessingAfter old; until then the file counts as being processed.
+func (s *Server) reprocessFileHandler(w http.ResponseWriter, r *http.Request) {
+	file, ok := s.requireProcessedFile(w, r)
+	if !ok {
+		return
+	}
+
+	if s.processor == nil || s.resolveFile == nil {
+		s.errorResponse(w, r, http.StatusServiceUnavailable, "reprocessing is not configured on this server")
+		return
+	}
+
+	toProcess, err := s.resolveFile(file.S3Path)
+	if err != nil {
+		s.badRequestResponse(w, r, err)
+		return
+	}
+
+	if _, running := s.reprocessing.LoadOrStore(file.S3Path, struct{}{}); running {
+		s.conflictResponse(w, r, "a reprocess of this file is already running")
+		return
+	}
+
+	if file.Status == data.FileStatusProcessing {
+		err := s.models.ProcessedFiles.MarkAbandoned(r.Context(), file.ID)
+		if err != nil {
+			s.reprocessing.Delete(file.S3Path)
+			switch {
+			case errors.Is(err, data.ErrEditConflict):
+				s.conflictResponse(w, r, "the file is currently being processed")
+			default:
+				s.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+
+	s.background(func() {
+		defer s.reprocessing.Delete(toProcess.S3Path)
+
+		result, err := s.processor.ReprocessFile(s.baseCtx, toProcess)
+		if err != nil {
+			s.logger.Error("reprocess failed", slog.String("s3_path", toProcess.S3Path), slog.String("error", err.Error()))
+			return
+		}
+
+		s.logger.Info("reprocess completed",
+			slog.String("s3_path", toProcess.S3Path),
+			slog.String("status", result.Status),
+			slog.Int("success_records", result.SuccessRecords),
+			slog.Int("error_records", result.ErrorRecords))
+	})
+
+	env := envelope{"message": "reprocessing started", "s3_path": file.S3Path}
+
+	err = s.writeJSON(w, http.StatusAccepted, env, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
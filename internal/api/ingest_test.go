@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/data/datatest"
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+// emptyFile is a FileReader for a file without records
+type emptyFile struct{}
+
+func (emptyFile) GetReader(context.Context, string) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+var processedFileColumns = []string{"id", "filename", "s3path", "processed_at", "records_count",
+	"errors_count", "status", "last_committed_line", "created_at", "updated_at"}
+
+func processedFileRow(id int64, status string) [][]driver.Value {
+	now := time.Now()
+	return [][]driver.Value{{id, "reviews.jl", "s3://bucket/reviews.jl", now, int64(10), int64(0), status, int64(10), now, now}}
+}
+
+func TestReprocessFileHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string // Status of the requested record, empty when it doesn't exist
+		interrupted bool   // Whether the path has an Interrupted record to resume
+		abandoned   bool   // Whether a Processing record is stale
+		wantStatus  int
+		wantRun     string // "new", "resume" or "" when no run starts
+	}{
+		{name: "not found", wantStatus: http.StatusNotFound},
+		{name: "new run", status: data.FileStatusSuccess, wantStatus: http.StatusAccepted, wantRun: "new"},
+		{name: "resumes interrupted", status: data.FileStatusFailed, interrupted: true, wantStatus: http.StatusAccepted, wantRun: "resume"},
+		{name: "processing", status: data.FileStatusProcessing, wantStatus: http.StatusConflict},
+		{name: "abandoned processing", status: data.FileStatusProcessing, abandoned: true, interrupted: true, wantStatus: http.StatusAccepted, wantRun: "resume"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cases []datatest.Case
+			if tt.status != "" {
+				cases = append(cases, datatest.Case{
+					Parts:  []string{"FROM processed_files", "WHERE id = $1"},
+					Result: datatest.Result{Columns: processedFileColumns, Rows: processedFileRow(7, tt.status)},
+				})
+			}
+			if tt.interrupted {
+				cases = append(cases, datatest.Case{
+					Parts:  []string{"FROM processed_files", "WHERE s3path = $1 AND status = $2"},
+					Result: datatest.Result{Columns: processedFileColumns, Rows: processedFileRow(7, data.FileStatusInterrupted)},
+				})
+			}
+			if tt.abandoned {
+				cases = append(cases, datatest.Case{
+					Parts:  []string{"UPDATE processed_files", "AND updated_at <"},
+					Result: datatest.Result{RowsAffected: 1},
+				})
+			}
+			cases = append(cases, datatest.Case{
+				Parts: []string{"INSERT INTO processed_files"},
+				Result: datatest.Result{
+					Columns: []string{"id", "created_at", "updated_at"},
+					Rows:    [][]driver.Value{{int64(8), time.Now(), time.Now()}},
+				},
+			})
+
+			db, recorder := datatest.Open(datatest.Match(cases...))
+			defer db.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			processor := jsonl_processing.NewJSONLProcessingService(db, nil, logger)
+			resolve := func(s3Path string) (jsonl_processing.FileToProcess, error) {
+				return jsonl_processing.FileToProcess{Filename: "reviews.jl", S3Path: s3Path, Reader: emptyFile{}}, nil
+			}
+			s := New(Config{}, logger, data.NewModels(db), processor, resolve)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/ingest/files/7/reprocess", nil)
+			r.SetPathValue("id", "7")
+			w := httptest.NewRecorder()
+			s.reprocessFileHandler(w, r)
+			s.wg.Wait()
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			created := recorder.Ran("INSERT INTO processed_files")
+			resumed := false
+			for _, stmt := range recorder.Statements() {
+				if strings.Contains(stmt.Query, "SET records_count") && stmt.Args[len(stmt.Args)-1] == int64(7) {
+					resumed = true
+				}
+			}
+			switch tt.wantRun {
+			case "new":
+				if !created {
+					t.Error("no processed file record was created for the new run")
+				}
+			case "resume":
+				if !resumed || created {
+					t.Error("the Interrupted record was not resumed")
+				}
+			default:
+				if created || recorder.Ran("UPDATE processed_files", "SET records_count") {
+					t.Error("a run was started")
+				}
+			}
+			if _, running := s.reprocessing.Load("s3://bucket/reviews.jl"); running {
+				t.Error("the path is still marked as reprocessing")
+			}
+		})
+	}
+}
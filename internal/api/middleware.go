@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+func (s *Server) recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				w.Header().Set("Connection", "close")
+				s.serverErrorResponse(w, r, fmt.Errorf("%s", err))
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+func (s *Server) logRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		s.logger.Info("request",
+			slog.String("method", r.Method),
+			slog.String("uri", r.URL.RequestURI()),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)))
+	})
+}
+
+// authenticate resolves an "Authorization: Bearer <key>" header to an API
+// key. Requests without the header continue anonymously; requireScope
+// decides whether that is allowed.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		authorizationHeader := r.Header.Get("Authorization")
+		if authorizationHeader == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scheme, plaintext, ok := strings.Cut(authorizationHeader, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || plaintext == "" {
+			s.invalidAPIKeyResponse(w, r)
+			return
+		}
+
+		key, err := s.models.APIKeys.GetForKey(r.Context(), plaintext)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				s.invalidAPIKeyResponse(w, r)
+			default:
+				s.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if err := s.models.APIKeys.TouchLastUsed(r.Context(), key.ID); err != nil {
+			s.logger.Warn("error recording api key use", slog.String("error", err.Error()))
+		}
+
+		next.ServeHTTP(w, s.contextSetAPIKey(r, key))
+	})
+}
+
+// requireScope only lets requests through whose API key grants scope
+func (s *Server) requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := s.contextGetAPIKey(r)
+
+		if key == nil {
+			s.authenticationRequiredResponse(w, r)
+			return
+		}
+
+		if !key.HasScope(scope) {
+			s.notPermittedResponse(w, r, scope)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// rateLimit applies the token bucket and daily quota of the authenticated
+// key. Anonymous requests are left to requireScope.
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := s.contextGetAPIKey(r)
+		if !s.config.RateLimit.Enabled || key == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limits := key.Limits.Or(data.EffectiveRateLimits{
+			RPS:        s.config.RateLimit.RPS,
+			Burst:      s.config.RateLimit.Burst,
+			DailyQuota: s.config.RateLimit.DailyQuota,
+		})
+
+		now := time.Now()
+		result, err := s.limiter.allow(r.Context(), key.ID, limits, now)
+		if err != nil {
+			s.serverErrorResponse(w, r, err)
+			return
+		}
+
+		setRateLimitHeaders(w, limits, result, now)
+
+		if !result.allowed {
+			if result.quotaExceeded {
+				s.quotaExceededResponse(w, r)
+			} else {
+				s.rateLimitExceededResponse(w, r)
+			}
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// RateLimitConfig holds the default per-key limits. Keys can override them,
+// see data.RateLimits.
+type RateLimitConfig struct {
+	Enabled    bool
+	RPS        float64 // Token bucket refill rate
+	Burst      int     // Token bucket size
+	DailyQuota int     // Requests per UTC day, 0 for no quota
+	Shared     bool    // Keep counters in Postgres instead of in process
+}
+
+// rateLimitResult is the outcome of one request against a key's limits
+type rateLimitResult struct {
+	allowed        bool
+	quotaExceeded  bool
+	tokens         float64
+	quotaRemaining int
+}
+
+// limiter counts requests per API key
+type limiter interface {
+	allow(ctx context.Context, id int64, limits data.EffectiveRateLimits, now time.Time) (rateLimitResult, error)
+}
+
+// memoryLimiter keeps a token bucket and a daily count per key. Each
+// replica enforces the limits on its own.
+type memoryLimiter struct {
+	mu      sync.Mutex
+	clients map[int64]*clientUsage
+}
+
+type clientUsage struct {
+	tokens   float64
+	lastSeen time.Time
+	day      string
+	requests int
+}
+
+func newMemoryLimiter() *memoryLimiter {
+	return &memoryLimiter{clients: make(map[int64]*clientUsage)}
+}
+
+func (l *memoryLimiter) allow(ctx context.Context, id int64, limits data.EffectiveRateLimits, now time.Time) (rateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client, found := l.clients[id]
+	if !found {
+		client = &clientUsage{tokens: float64(limits.Burst), lastSeen: now}
+		l.clients[id] = client
+	}
+
+	client.tokens = math.Min(float64(limits.Burst), client.tokens+now.Sub(client.lastSeen).Seconds()*limits.RPS)
+	client.lastSeen = now
+
+	if day := now.UTC().Format(time.DateOnly); client.day != day {
+		client.day = day
+		client.requests = 0
+	}
+
+	// Rejected requests take neither a token nor a request from the quota
+	if limits.DailyQuota > 0 && client.requests >= limits.DailyQuota {
+		return rateLimitResult{quotaExceeded: true, tokens: client.tokens}, nil
+	}
+	if client.tokens < 1 {
+		return rateLimitResult{tokens: client.tokens, quotaRemaining: quotaRemaining(limits, client.requests)}, nil
+	}
+
+	client.tokens--
+	client.requests++
+
+	return rateLimitResult{allowed: true, tokens: client.tokens, quotaRemaining: quotaRemaining(limits, client.requests)}, nil
+}
+
+// cleanup drops keys that have been idle long enough for their bucket to
+// be full again and whose daily count has expired
+func (l *memoryLimiter) cleanup(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	today := now.UTC().Format(time.DateOnly)
+	for id, client := range l.clients {
+		if client.day != today && now.Sub(client.lastSeen) > time.Hour {
+			delete(l.clients, id)
+		}
+	}
+}
+
+// postgresLimiter shares the counters between replicas through the
+// database, at the cost of one or two queries per request
+type postgresLimiter struct {
+	models data.RateLimitModel
+}
+
+func (l *postgresLimiter) allow(ctx context.Context, id int64, limits data.EffectiveRateLimits, now time.Time) (rateLimitResult, error) {
+	allowed, tokens, err := l.models.TakeToken(ctx, id, limits.RPS, limits.Burst)
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+
+	if !allowed {
+		return rateLimitResult{tokens: tokens, quotaRemaining: -1}, nil
+	}
+
+	if limits.DailyQuota == 0 {
+		return rateLimitResult{allowed: true, tokens: tokens, quotaRemaining: -1}, nil
+	}
+
+	requests, counted, err := l.models.CountRequest(ctx, id, now, limits.DailyQuota)
+	if err != nil {
+		return rateLimitResult{}, err
+	}
+
+	if !counted {
+		// Only admitted requests are charged, so give the token back
+		if err := l.models.ReturnToken(ctx, id, limits.Burst); err != nil {
+			return rateLimitResult{}, err
+		}
+		return rateLimitResult{quotaExceeded: true, tokens: math.Min(float64(limits.Burst), tokens+1)}, nil
+	}
+
+	return rateLimitResult{allowed: true, tokens: tokens, quotaRemaining: quotaRemaining(limits, requests)}, nil
+}
+
+// quotaRemaining returns the requests left today, or -1 when the key has
+// no quota
+func quotaRemaining(limits data.EffectiveRateLimits, requests int) int {
+	if limits.DailyQuota == 0 {
+		return -1
+	}
+	return max(0, limits.DailyQuota-requests)
+}
+
+// runLimiterCleanup periodically drops stale limiter state until ctx is done
+func (s *Server) runLimiterCleanup(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			switch l := s.limiter.(type) {
+			case *memoryLimiter:
+				l.cleanup(now)
+			case *postgresLimiter:
+				// Keep yesterday for clients near a day boundary
+				if err := l.models.DeleteUsageBefore(ctx, now.AddDate(0, 0, -1)); err != nil && ctx.Err() == nil {
+					s.logger.Warn("error deleting old api key usage", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}
+}
+
+// setRateLimitHeaders writes the RateLimit headers of the IETF draft. The
+// RateLimit-* values describe whichever limit is closest to running out.
+func setRateLimitHeaders(w http.ResponseWriter, limits data.EffectiveRateLimits, result rateLimitResult, now time.Time) {
+	burstWindow := int(math.Ceil(float64(limits.Burst) / limits.RPS))
+	policy := fmt.Sprintf("%d;w=%d", limits.Burst, burstWindow)
+
+	limit := limits.Burst
+	remaining := int(math.Max(0, math.Floor(result.tokens)))
+	reset := int(math.Ceil((float64(limits.Burst) - result.tokens) / limits.RPS))
+	if remaining == 0 {
+		// Time until the next token rather than a full bucket
+		reset = int(math.Ceil((1 - result.tokens) / limits.RPS))
+	}
+
+	if limits.DailyQuota > 0 {
+		policy += fmt.Sprintf(", %d;w=86400", limits.DailyQuota)
+
+		if result.quotaExceeded || (result.quotaRemaining >= 0 && result.quotaRemaining < remaining) {
+			limit = limits.DailyQuota
+			remaining = max(0, result.quotaRemaining)
+			tomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+			reset = int(math.Ceil(tomorrow.Sub(now).Seconds()))
+		}
+	}
+
+	w.Header().Set("RateLimit-Policy", policy)
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(max(0, reset)))
+
+	if !result.allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, reset)))
+	}
+}
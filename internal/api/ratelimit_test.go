@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/data/datatest"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	start := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	type step struct {
+		at            time.Duration // From start
+		allowed       bool
+		quotaExceeded bool
+		tokens        float64 // Left afterwards
+	}
+
+	tests := []struct {
+		name   string
+		limits data.EffectiveRateLimits
+		steps  []step
+	}{
+		{
+			name:   "burst then refill",
+			limits: data.EffectiveRateLimits{RPS: 1, Burst: 2},
+			steps: []step{
+				{0, true, false, 1},
+				{0, true, false, 0},
+				{0, false, false, 0},
+				{time.Second, true, false, 0},
+				{5 * time.Second, true, false, 1},
+			},
+		},
+		{
+			name:   "quota exceeded takes no token",
+			limits: data.EffectiveRateLimits{RPS: 1, Burst: 5, DailyQuota: 2},
+			steps: []step{
+				{0, true, false, 4},
+				{0, true, false, 3},
+				{0, false, true, 3},
+				{0, false, true, 3},
+				{0, false, true, 3},
+			},
+		},
+		{
+			name:   "retries don't extend the lockout",
+			limits: data.EffectiveRateLimits{RPS: 1, Burst: 1, DailyQuota: 1},
+			steps: []step{
+				{0, true, false, 0},
+				{time.Hour, false, true, 1},
+				{2 * time.Hour, false, true, 1},
+				// The quota resets at midnight UTC
+				{12 * time.Hour, true, false, 0},
+			},
+		},
+		{
+			name:   "rate limited requests don't count towards the quota",
+			limits: data.EffectiveRateLimits{RPS: 1, Burst: 1, DailyQuota: 2},
+			steps: []step{
+				{0, true, false, 0},
+				{0, false, false, 0},
+				{0, false, false, 0},
+				{time.Second, true, false, 0},
+				{2 * time.Second, false, true, 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newMemoryLimiter()
+			for i, step := range tt.steps {
+				result, err := l.allow(context.Background(), 1, tt.limits, start.Add(step.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.allowed != step.allowed || result.quotaExceeded != step.quotaExceeded || result.tokens != step.tokens {
+					t.Errorf("request %d: allowed = %v, quotaExceeded = %v, tokens = %v, want %v, %v, %v",
+						i+1, result.allowed, result.quotaExceeded, result.tokens, step.allowed, step.quotaExceeded, step.tokens)
+				}
+			}
+		})
+	}
+}
+
+func TestPostgresLimiter(t *testing.T) {
+	errUsage := errors.New("usage table locked")
+
+	tests := []struct {
+		name          string
+		limits        data.EffectiveRateLimits
+		tokenAllowed  bool
+		usage         datatest.Result // Answer to the quota count
+		wantErr       error
+		wantAllowed   bool
+		wantExceeded  bool
+		wantRemaining int
+		wantCounted   bool // Whether the quota count was attempted
+		wantReturned  bool // Whether the token was put back
+	}{
+		{
+			name:          "no token",
+			limits:        data.EffectiveRateLimits{RPS: 1, Burst: 5, DailyQuota: 10},
+			wantRemaining: -1,
+		},
+		{
+			name:          "no quota",
+			limits:        data.EffectiveRateLimits{RPS: 1, Burst: 5},
+			tokenAllowed:  true,
+			wantAllowed:   true,
+			wantRemaining: -1,
+		},
+		{
+			name:          "within quota",
+			limits:        data.EffectiveRateLimits{RPS: 1, Burst: 5, DailyQuota: 10},
+			tokenAllowed:  true,
+			usage:         datatest.Result{Columns: []string{"requests"}, Rows: [][]driver.Value{{int64(4)}}},
+			wantAllowed:   true,
+			wantRemaining: 6,
+			wantCounted:   true,
+		},
+		{
+			name:         "quota used up",
+			limits:       data.EffectiveRateLimits{RPS: 1, Burst: 5, DailyQuota: 10},
+			tokenAllowed: true,
+			usage:        datatest.Result{Columns: []string{"requests"}},
+			wantExceeded: true,
+			wantCounted:  true,
+			wantReturned: true,
+		},
+		{
+			name:         "count fails",
+			limits:       data.EffectiveRateLimits{RPS: 1, Burst: 5, DailyQuota: 10},
+			tokenAllowed: true,
+			usage:        datatest.Result{Err: errUsage},
+			wantErr:      errUsage,
+			wantCounted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := datatest.Open(datatest.Match(
+				datatest.Case{
+					Parts: []string{"INSERT INTO api_key_buckets"},
+					Result: datatest.Result{
+						Columns: []string{"allowed", "tokens"},
+						Rows:    [][]driver.Value{{tt.tokenAllowed, 2.0}},
+					},
+				},
+				datatest.Case{Parts: []string{"INSERT INTO api_key_usage"}, Result: tt.usage},
+				datatest.Case{Parts: []string{"UPDATE api_key_buckets"}, Result: datatest.Result{RowsAffected: 1}},
+			))
+			defer db.Close()
+
+			l := &postgresLimiter{models: data.NewModels(db).RateLimits}
+			result, err := l.allow(context.Background(), 1, tt.limits, time.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if result.allowed != tt.wantAllowed || result.quotaExceeded != tt.wantExceeded {
+				t.Errorf("allowed = %v, quotaExceeded = %v, want %v, %v", result.allowed, result.quotaExceeded, tt.wantAllowed, tt.wantExceeded)
+			}
+			if !tt.wantExceeded && result.quotaRemaining != tt.wantRemaining {
+				t.Errorf("quotaRemaining = %d, want %d", result.quotaRemaining, tt.wantRemaining)
+			}
+			if tt.wantReturned && result.tokens != 3 {
+				t.Errorf("tokens = %v, want the returned token counted", result.tokens)
+			}
+			if got := recorder.Ran("INSERT INTO api_key_usage"); got != tt.wantCounted {
+				t.Errorf("quota counted = %v, want %v", got, tt.wantCounted)
+			}
+			if got := recorder.Ran("UPDATE api_key_buckets"); got != tt.wantReturned {
+				t.Errorf("token returned = %v, want %v", got, tt.wantReturned)
+			}
+		})
+	}
+}
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	// An hour before midnight UTC, when the daily quota resets
+	now := time.Date(2024, 5, 10, 23, 0, 0, 0, time.UTC)
+	bucket := data.EffectiveRateLimits{RPS: 2, Burst: 10}
+	withQuota := data.EffectiveRateLimits{RPS: 2, Burst: 10, DailyQuota: 1000}
+
+	tests := []struct {
+		name       string
+		limits     data.EffectiveRateLimits
+		result     rateLimitResult
+		want       map[string]string
+		retryAfter string // Empty when the header must not be set
+	}{
+		{
+			name:   "bucket",
+			limits: bucket,
+			result: rateLimitResult{allowed: true, tokens: 7.5, quotaRemaining: -1},
+			want: map[string]string{
+				"RateLimit-Policy": "10;w=5", "RateLimit-Limit": "10",
+				"RateLimit-Remaining": "7", "RateLimit-Reset": "2",
+			},
+		},
+		{
+			name:   "bucket empty",
+			limits: bucket,
+			result: rateLimitResult{tokens: 0.5, quotaRemaining: -1},
+			want: map[string]string{
+				"RateLimit-Limit": "10", "RateLimit-Remaining": "0", "RateLimit-Reset": "1",
+			},
+			retryAfter: "1",
+		},
+		{
+			name:   "bucket closer to running out",
+			limits: withQuota,
+			result: rateLimitResult{allowed: true, tokens: 7, quotaRemaining: 500},
+			want: map[string]string{
+				"RateLimit-Policy": "10;w=5, 1000;w=86400", "RateLimit-Limit": "10",
+				"RateLimit-Remaining": "7", "RateLimit-Reset": "2",
+			},
+		},
+		{
+			name:   "quota closer to running out",
+			limits: withQuota,
+			result: rateLimitResult{allowed: true, tokens: 7, quotaRemaining: 3},
+			want: map[string]string{
+				"RateLimit-Limit": "1000", "RateLimit-Remaining": "3", "RateLimit-Reset": "3600",
+			},
+		},
+		{
+			name:   "quota exceeded",
+			limits: withQuota,
+			result: rateLimitResult{quotaExceeded: true, tokens: 7},
+			want: map[string]string{
+				"RateLimit-Limit": "1000", "RateLimit-Remaining": "0", "RateLimit-Reset": "3600",
+			},
+			retryAfter: "3600",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			setRateLimitHeaders(w, tt.limits, tt.result, now)
+
+			for header, want := range tt.want {
+				if got := w.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+			if policy := w.Header().Get("RateLimit-Policy"); strings.Contains(policy, "86400") != (tt.limits.DailyQuota > 0) {
+				t.Errorf("RateLimit-Policy = %q, want the quota listed only when there is one", policy)
+			}
+		})
+	}
+}
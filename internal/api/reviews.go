@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) listHotelReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	input := data.ReviewFilter{HotelID: hotel.HotelID}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.ProviderID = s.readOptionalInt(qs, "provider_id", v)
+	input.MinRating = s.readOptionalFloat(qs, "min_rating", v)
+	input.MaxRating = s.readOptionalFloat(qs, "max_rating", v)
+	input.From = s.readOptionalDate(qs, "from", v)
+	input.To = s.readOptionalDate(qs, "to", v)
+	input.CountryID = s.readOptionalInt(qs, "country_id", v)
+	input.ReviewGroupID = s.readOptionalInt(qs, "review_group_id", v)
+	input.RoomType = s.readString(qs, "room_type", "")
+	input.RoomCategory = s.readString(qs, "room_category", "")
+	input.HasResponse = s.readOptionalBool(qs, "has_response", v)
+	input.MinSentiment = s.readOptionalFloat(qs, "min_sentiment", v)
+	input.MaxSentiment = s.readOptionalFloat(qs, "max_sentiment", v)
+	input.Language = s.readString(qs, "language", "")
+	if expert := s.readOptionalBool(qs, "expert", v); expert != nil {
+		input.ExpertOnly = *expert
+	}
+
+	input.Sort = s.readString(qs, "sort", "-review_date")
+	input.Cursor = s.readString(qs, "cursor", "")
+	input.PageSize = s.readInt(qs, "page_size", 20, v)
+
+	if data.ValidateReviewFilter(v, input); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	reviews, nextCursor, err := s.models.Review.List(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidCursor):
+			v.AddError("cursor", "is invalid or does not match the sort")
+			s.failedValidationResponse(w, r, v.Errors)
+		default:
+			s.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	metadata := envelope{
+		"page_size":   input.PageSize,
+		"next_cursor": nextCursor,
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"reviews": reviews, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+)
+
+func (s *Server) listHotelRoomTypesHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	roomTypes, err := s.models.RoomTypes.GetForHotel(r.Context(), hotel.HotelID)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"room_types": roomTypes}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
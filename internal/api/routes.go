@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// Route is an endpoint of the API
+type Route struct {
+	Method  string
+	Pattern string
+	Scope   string // API key scope required, empty for public endpoints
+
+	handler http.HandlerFunc
+}
+
+func (s *Server) routeTable() []Route {
+	return []Route{
+		{http.MethodGet, "/v1/healthcheck", "", s.healthcheckHandler},
+		{http.MethodGet, "/v1/openapi.json", "", s.openAPIHandler},
+
+		{http.MethodGet, "/v1/hotels", data.ScopeReviewsRead, s.listHotelsHandler},
+		{http.MethodGet, "/v1/hotels/{hotel_id}", data.ScopeReviewsRead, s.cacheHotelResponse(s.showHotelHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/reviews", data.ScopeReviewsRead, s.cacheHotelResponse(s.listHotelReviewsHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/stats", data.ScopeReviewsRead, s.cacheHotelResponse(s.showHotelStatsHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/trends", data.ScopeReviewsRead, s.cacheHotelResponse(s.showHotelTrendsHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/aspects", data.ScopeReviewsRead, s.cacheHotelResponse(s.showHotelAspectsHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/room-types", data.ScopeReviewsRead, s.cacheHotelResponse(s.listHotelRoomTypesHandler)},
+		{http.MethodGet, "/v1/hotels/{hotel_id}/grades", data.ScopeReviewsRead, s.compareHotelGradesHandler},
+
+		{http.MethodGet, "/v1/reviews/search", data.ScopeReviewsRead, s.searchReviewsHandler},
+
+		{http.MethodGet, "/v1/alerts", data.ScopeReviewsRead, s.listAlertsHandler},
+		{http.MethodGet, "/v1/reports/duplication", data.ScopeReviewsRead, s.showDuplicationReportHandler},
+
+		{http.MethodGet, "/v1/ingest/files", data.ScopeIngestAdmin, s.listProcessedFilesHandler},
+		{http.MethodGet, "/v1/ingest/files/{id}", data.ScopeIngestAdmin, s.showProcessedFileHandler},
+		{http.MethodGet, "/v1/ingest/files/{id}/errors", data.ScopeIngestAdmin, s.listProcessedFileErrorsHandler},
+		{http.MethodPost, "/v1/ingest/files/{id}/reprocess", data.ScopeIngestAdmin, s.reprocessFileHandler},
+	}
+}
+
+// Routes lists the endpoints the server registers, for checking them
+// against the OpenAPI document
+func Routes() []Route {
+	return (&Server{}).routeTable()
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	for _, route := range s.routeTable() {
+		handler := route.handler
+		if route.Scope != "" {
+			handler = s.requireScope(route.Scope, handler)
+		}
+		mux.HandleFunc(route.Method+" "+route.Pattern, handler)
+	}
+
+	return s.recoverPanic(s.logRequest(s.authenticate(s.rateLimit(mux))))
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) searchReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.ReviewSearch
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Query = s.readString(qs, "q", "")
+	input.Language = s.readString(qs, "lang", "en")
+
+	if hotelID := s.readOptionalInt(qs, "hotel_id", v); hotelID != nil {
+		id := int64(*hotelID)
+		input.HotelID = &id
+	}
+
+	input.Filters.Page = s.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = s.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = "-rank"
+	input.Filters.SortSafelist = []string{"-rank"}
+
+	if data.ValidateReviewSearch(v, input); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	results, metadata, err := s.models.Review.Search(r.Context(), input)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"results": results, "metadata": metadata}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
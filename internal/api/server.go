@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/service/jsonl_processing"
+)
+
+const version = "1.0.0"
+
+type Config struct {
+	Port            int
+	Env             string
+	ShutdownTimeout time.Duration
+	GradeScales     map[string]float64 // Maximum grade per provider, see data.GradeComparisonOptions
+	RateLimit       RateLimitConfig
+	CacheBytes      int // Size of the hotel response cache, 0 disables it
+}
+
+// FileResolver maps an S3 path to the source it was ingested from, so a
+// reprocessed file is read with the same credentials and platform override
+type FileResolver func(s3Path string) (jsonl_processing.FileToProcess, error)
+
+// Server is the read API over the imported hotel and review data, plus the
+// ingestion admin endpoints
+type Server struct {
+	config      Config
+	logger      *slog.Logger
+	models      data.Models
+	processor   *jsonl_processing.JSONLProcessingService
+	resolveFile FileResolver
+	limiter     limiter
+	cache       *responseCache
+
+	baseCtx      context.Context // Cancelled on shutdown, parent of background jobs
+	wg           sync.WaitGroup
+	reprocessing sync.Map // S3 paths with a reprocess job in flight
+}
+
+func New(cfg Config, logger *slog.Logger, models data.Models, processor *jsonl_processing.JSONLProcessingService, resolveFile FileResolver) *Server {
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 20 * time.Second
+	}
+
+	var l limiter = newMemoryLimiter()
+	if cfg.RateLimit.Shared {
+		l = &postgresLimiter{models: models.RateLimits}
+	}
+
+	s := &Server{
+		config:      cfg,
+		logger:      logger,
+		models:      models,
+		processor:   processor,
+		resolveFile: resolveFile,
+		limiter:     l,
+		baseCtx:     context.Background(),
+	}
+
+	if cfg.CacheBytes > 0 {
+		s.cache = newResponseCache(cfg.CacheBytes)
+
+		// Imports in other processes are caught by the version check
+		if processor != nil {
+			processor.OnBatchCommit(s.cache.evictHotels)
+		}
+	}
+
+	return s
+}
+
+// Serve listens until ctx is cancelled, then gives in-flight requests
+// ShutdownTimeout to complete
+func (s *Server) Serve(ctx context.Context) error {
+	s.baseCtx = ctx
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", s.config.Port),
+		Handler:      s.routes(),
+		IdleTimeout:  time.Minute,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		ErrorLog:     slog.NewLogLogger(s.logger.Handler(), slog.LevelError),
+	}
+
+	if s.config.RateLimit.Enabled {
+		go s.runLimiterCleanup(ctx)
+	}
+
+	shutdownError := make(chan error, 1)
+
+	go func() {
+		<-ctx.Done()
+
+		s.logger.Info("shutting down server")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+		defer cancel()
+
+		err := srv.Shutdown(shutdownCtx)
+
+		// Background jobs see the cancelled ctx and record their state
+		s.logger.Info("completing background tasks")
+		s.wg.Wait()
+		shutdownError <- err
+	}()
+
+	s.logger.Info("starting server", slog.String("addr", srv.Addr), slog.String("env", s.config.Env))
+
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if err := <-shutdownError; err != nil {
+		return err
+	}
+
+	s.logger.Info("stopped server", slog.String("addr", srv.Addr))
+
+	return nil
+}
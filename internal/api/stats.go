@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// readDateWindow reads the from/to query string values into a DateWindow
+func (s *Server) readDateWindow(r *http.Request, v *validator.Validator) data.DateWindow {
+	qs := r.URL.Query()
+
+	window := data.DateWindow{
+		From: s.readOptionalDate(qs, "from", v),
+		To:   s.readOptionalDate(qs, "to", v),
+	}
+
+	if window.From != nil && window.To != nil {
+		v.Check(window.From.Before(*window.To), "from", "must be before to")
+	}
+
+	return window
+}
+
+func (s *Server) showHotelStatsHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	window := s.readDateWindow(r, v)
+	language := s.readString(r.URL.Query(), "language", "")
+	if data.ValidateLanguage(v, "language", language); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	stats, err := s.models.Analytics.HotelStats(r.Context(), hotel.HotelID, window, language)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"stats": stats}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
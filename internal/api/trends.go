@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func (s *Server) showHotelTrendsHandler(w http.ResponseWriter, r *http.Request) {
+	hotel, ok := s.requireHotel(w, r)
+	if !ok {
+		return
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	query := data.TrendQuery{
+		HotelID:  hotel.HotelID,
+		Interval: s.readString(qs, "interval", data.TrendMonth),
+		SplitBy:  s.readString(qs, "split", data.TrendSplitNone),
+		Window:   s.readDateWindow(r, v),
+		Rolling:  s.readInt(qs, "rolling", 3, v),
+	}
+
+	if data.ValidateTrendQuery(v, query); !v.Valid() {
+		s.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	trend, err := s.models.Analytics.RatingTrend(r.Context(), query)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = s.writeJSON(w, http.StatusOK, envelope{"trend": trend}, nil)
+	if err != nil {
+		s.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package aspects tags review text with the hospitality aspects it talks
+// about, such as cleanliness, staff or wifi, using a keyword and phrase
+// dictionary with synonyms in several languages.
+package aspects
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Polarities of a mention, taken from the field it was found in
+const (
+	Positive = "positive"
+	Negative = "negative"
+)
+
+//go:embed dictionary.json
+var defaultDictionary []byte
+
+// Mention counts how often an aspect is mentioned with one polarity
+type Mention struct {
+	Aspect   string
+	Polarity string
+	Count    int
+}
+
+// Dictionary maps keywords and phrases to aspects. The file is a JSON
+// object of aspect name to language code to terms, e.g.
+//
+//	{"wifi": {"en": ["wifi", "internet"], "de": ["wlan"]}}
+//
+// Terms match whole words, case insensitively. A trailing * on a word
+// matches any word starting with it, so "clean*" matches "cleanliness".
+type Dictionary struct {
+	// Version identifies the dictionary contents. Reviews store the version
+	// that tagged them so `aspects backfill` can retag after a change.
+	Version string
+
+	aspects []string
+	byWord  map[string][]term // Terms keyed by their first word
+	byStem  []term            // Terms whose first word ends in *
+}
+
+type term struct {
+	aspect string
+	words  []string // A trailing * on a word makes it a prefix
+}
+
+// Default returns the dictionary embedded in the binary
+var Default = sync.OnceValue(func() *Dictionary {
+	d, err := Parse(defaultDictionary)
+	if err != nil {
+		panic(fmt.Sprintf("embedded aspect dictionary: %v", err))
+	}
+	return d
+})
+
+// Load reads a dictionary file. An empty path returns the default.
+func Load(path string) (*Dictionary, error) {
+	if path == "" {
+		return Default(), nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// Parse parses a dictionary file
+func Parse(b []byte) (*Dictionary, error) {
+	var file map[string]map[string][]string
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	if len(file) == 0 {
+		return nil, fmt.Errorf("dictionary has no aspects")
+	}
+
+	sum := sha256.Sum256(b)
+	d := &Dictionary{
+		Version: hex.EncodeToString(sum[:6]),
+		byWord:  make(map[string][]term),
+	}
+
+	for aspect, languages := range file {
+		if aspect == "" {
+			return nil, fmt.Errorf("aspect name must not be empty")
+		}
+		d.aspects = append(d.aspects, aspect)
+
+		for lang, terms := range languages {
+			for _, text := range terms {
+				words := tokenize(text, true)
+				if len(words) == 0 || slices.Contains(words, "*") {
+					return nil, fmt.Errorf("%s.%s: term %q has no words", aspect, lang, text)
+				}
+
+				t := term{aspect: aspect, words: words}
+				if strings.HasSuffix(words[0], "*") {
+					d.byStem = append(d.byStem, t)
+				} else {
+					d.byWord[words[0]] = append(d.byWord[words[0]], t)
+				}
+			}
+		}
+	}
+	slices.Sort(d.aspects)
+
+	return d, nil
+}
+
+// Aspects returns the aspect names in order
+func (d *Dictionary) Aspects() []string {
+	return slices.Clone(d.aspects)
+}
+
+// Extract tags the liked and disliked text of a review. Mentions in
+// positives are positive and mentions in negatives are negative. Mentions
+// are sorted by aspect, then polarity.
+func (d *Dictionary) Extract(positives, negatives string) []Mention {
+	mentions := []Mention{}
+
+	for _, field := range []struct {
+		text     string
+		polarity string
+	}{
+		{negatives, Negative},
+		{positives, Positive},
+	} {
+		for aspect, count := range d.Match(field.text) {
+			mentions = append(mentions, Mention{Aspect: aspect, Polarity: field.polarity, Count: count})
+		}
+	}
+
+	slices.SortFunc(mentions, func(a, b Mention) int {
+		if c := strings.Compare(a.Aspect, b.Aspect); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Polarity, b.Polarity)
+	})
+
+	return mentions
+}
+
+// Match counts the terms of each aspect found in text. Several terms of
+// one aspect at the same position count once.
+func (d *Dictionary) Match(text string) map[string]int {
+	counts := make(map[string]int)
+
+	words := tokenize(text, false)
+	for i, word := range words {
+		matched := make(map[string]bool)
+
+		for _, t := range d.byWord[word] {
+			if !matched[t.aspect] && t.matches(words[i:]) {
+				matched[t.aspect] = true
+			}
+		}
+		for _, t := range d.byStem {
+			if !matched[t.aspect] && t.matches(words[i:]) {
+				matched[t.aspect] = true
+			}
+		}
+
+		for aspect := range matched {
+			counts[aspect]++
+		}
+	}
+
+	return counts
+}
+
+func (t term) matches(words []string) bool {
+	if len(words) < len(t.words) {
+		return false
+	}
+
+	for i, want := range t.words {
+		if prefix, ok := strings.CutSuffix(want, "*"); ok {
+			if !strings.HasPrefix(words[i], prefix) {
+				return false
+			}
+		} else if words[i] != want {
+			return false
+		}
+	}
+
+	return true
+}
+
+// tokenize lower cases text and splits it into words of letters and
+// digits. Dictionary terms keep a trailing * for prefix matching.
+func tokenize(text string, keepStar bool) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		if keepStar && r == '*' {
+			return false
+		}
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,143 @@
+package aspects
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+const testDictionary = `{
+	"cleanliness": {"en": ["clean*", "dirty", "bed bugs"], "de": ["sauber*"]},
+	"staff": {"en": ["staff", "front desk", "friendly"]},
+	"wifi": {"en": ["wifi", "internet"], "de": ["wlan"]}
+}`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"valid", testDictionary, false},
+		{"not json", `{"wifi": [`, true},
+		{"no aspects", `{}`, true},
+		{"empty aspect name", `{"": {"en": ["wifi"]}}`, true},
+		{"term without words", `{"wifi": {"en": [" - "]}}`, true},
+		{"lone star", `{"wifi": {"en": ["free *"]}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDictionaryVersion(t *testing.T) {
+	a, err := Parse([]byte(testDictionary))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse([]byte(`{"wifi": {"en": ["wifi"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Version == "" || a.Version == b.Version {
+		t.Errorf("Version = %q and %q, want distinct versions", a.Version, b.Version)
+	}
+	if got, want := a.Aspects(), []string{"cleanliness", "staff", "wifi"}; !slices.Equal(got, want) {
+		t.Errorf("Aspects() = %v, want %v", got, want)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	d, err := Parse([]byte(testDictionary))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want map[string]int
+	}{
+		{"empty", "", map[string]int{}},
+		{"no terms", "Nice view of the sea", map[string]int{}},
+		{"exact word", "Slow wifi and internet", map[string]int{"wifi": 2}},
+		{"case and punctuation", "WIFI!!! Staff, friendly.", map[string]int{"wifi": 1, "staff": 2}},
+		{"prefix", "Cleanliness was great, very clean", map[string]int{"cleanliness": 2}},
+		{"prefix needs word start", "Unclean room", map[string]int{}},
+		{"phrase", "Bed bugs at the front desk", map[string]int{"cleanliness": 1, "staff": 1}},
+		{"partial phrase", "The bed was soft, the desk small", map[string]int{}},
+		{"other language", "Sauberes Zimmer, schnelles WLAN", map[string]int{"cleanliness": 1, "wifi": 1}},
+		{"whole words only", "Dirtyish staffing", map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Match(tt.text); !maps.Equal(got, tt.want) {
+				t.Errorf("Match(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	d, err := Parse([]byte(testDictionary))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                 string
+		positives, negatives string
+		want                 []Mention
+	}{
+		{"empty", "", "", []Mention{}},
+		{
+			name:      "polarity from field",
+			positives: "Friendly staff",
+			negatives: "Dirty room, no wifi",
+			want: []Mention{
+				{Aspect: "cleanliness", Polarity: Negative, Count: 1},
+				{Aspect: "staff", Polarity: Positive, Count: 2},
+				{Aspect: "wifi", Polarity: Negative, Count: 1},
+			},
+		},
+		{
+			name:      "same aspect in both fields",
+			positives: "Clean room",
+			negatives: "Dirty bathroom",
+			want: []Mention{
+				{Aspect: "cleanliness", Polarity: Negative, Count: 1},
+				{Aspect: "cleanliness", Polarity: Positive, Count: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := d.Extract(tt.positives, tt.negatives); !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q, %q) = %v, want %v", tt.positives, tt.negatives, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	d := Default()
+	for _, aspect := range []string{"cleanliness", "staff"} {
+		if !slices.Contains(d.Aspects(), aspect) {
+			t.Errorf("Aspects() = %v, want %q", d.Aspects(), aspect)
+		}
+	}
+
+	got := d.Match("Das Personal war freundlich und das Zimmer sauber")
+	if got["staff"] == 0 || got["cleanliness"] == 0 {
+		t.Errorf("Match() = %v, want staff and cleanliness", got)
+	}
+}
@@ -0,0 +1,427 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// Alert kinds
+const (
+	AlertRatingDrop       = "rating_drop"        // Recent reviews rate well below the hotel's baseline
+	AlertOverallScoreDrop = "overall_score_drop" // A provider's overall score fell between imports
+)
+
+// Alert severities
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert statuses used to filter the list
+const (
+	AlertStatusOpen     = "open"
+	AlertStatusResolved = "resolved"
+)
+
+// Alert is an anomaly detected in a hotel's ratings. Baseline and Observed
+// are mean normalized ratings for rating drops and normalized overall
+// scores for score drops, both 0-100; Score is the z-score or the size of
+// the drop.
+type Alert struct {
+	ID         int64      `json:"id"`
+	HotelID    int64      `json:"hotel_id"`
+	ProviderID *int       `json:"provider_id"`
+	Kind       string     `json:"kind"`
+	Severity   string     `json:"severity"`
+	Message    string     `json:"message"`
+	Baseline   float64    `json:"baseline"`
+	Observed   float64    `json:"observed"`
+	Score      float64    `json:"score"`
+	DetectedAt time.Time  `json:"detected_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// AnomalyOptions tunes the detector. The recent window is the last
+// RecentDays of a hotel's reviews, counted back from its latest review so
+// historical imports are judged against their own timeline, and the
+// baseline is the BaselineDays before that.
+type AnomalyOptions struct {
+	RecentDays   int
+	BaselineDays int
+	MinRecent    int     // Reviews the recent window needs before it is judged
+	MinBaseline  int     // Reviews the baseline needs before it is trusted
+	ZThreshold   float64 // z-score of the recent mean at or below -ZThreshold raises a warning
+	ScoreDrop    float64 // Normalized overall score drop, 0-100, that raises a warning
+}
+
+func DefaultAnomalyOptions() AnomalyOptions {
+	return AnomalyOptions{
+		RecentDays:   30,
+		BaselineDays: 365,
+		MinRecent:    5,
+		MinBaseline:  20,
+		ZThreshold:   3,
+		ScoreDrop:    3,
+	}
+}
+
+func ValidateAnomalyOptions(v *validator.Validator, opts AnomalyOptions) {
+	v.Check(opts.RecentDays > 0, "recent_days", "must be greater than zero")
+	v.Check(opts.BaselineDays > 0, "baseline_days", "must be greater than zero")
+	v.Check(opts.MinRecent > 1, "min_recent", "must be greater than one")
+	v.Check(opts.MinBaseline > 1, "min_baseline", "must be greater than one")
+	v.Check(opts.ZThreshold > 0, "z_threshold", "must be greater than zero")
+	v.Check(opts.ScoreDrop > 0, "score_drop", "must be greater than zero")
+}
+
+// Critical alerts are raised at criticalFactor times the warning threshold
+const criticalFactor = 1.5
+
+// minStdDev keeps the z-score finite for a baseline of identical ratings
+const minStdDev = 2.5
+
+type AlertModel struct {
+	DB DBTX
+}
+
+// AlertFilter narrows GetAll. Empty fields are not applied.
+type AlertFilter struct {
+	HotelID  *int64
+	Kind     string
+	Severity string
+	Status   string
+	Filters
+}
+
+func ValidateAlertFilter(v *validator.Validator, f AlertFilter) {
+	if f.Kind != "" {
+		v.Check(validator.PermittedValue(f.Kind, AlertRatingDrop, AlertOverallScoreDrop), "kind", "invalid kind value")
+	}
+	if f.Severity != "" {
+		v.Check(validator.PermittedValue(f.Severity, SeverityWarning, SeverityCritical), "severity", "invalid severity value")
+	}
+	if f.Status != "" {
+		v.Check(validator.PermittedValue(f.Status, AlertStatusOpen, AlertStatusResolved), "status", "invalid status value")
+	}
+
+	ValidateFilters(v, f.Filters)
+}
+
+// GetAll returns one page of alerts matching the filter along with the
+// pagination metadata
+func (m AlertModel) GetAll(ctx context.Context, filter AlertFilter) ([]*Alert, Metadata, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, hotel_id, provider_id, kind, severity, message,
+		baseline, observed, score, detected_at, updated_at, resolved_at
+	FROM alerts
+	WHERE ($1::bigint IS NULL OR hotel_id = $1)
+	AND ($2 = '' OR kind = $2)
+	AND ($3 = '' OR severity = $3)
+	AND ($4 = '' OR (resolved_at IS NULL) = ($4 = 'open'))
+	ORDER BY %s %s, id DESC
+	LIMIT $5 OFFSET $6`, filter.sortColumn(), filter.sortDirection())
+
+	args := []interface{}{
+		filter.HotelID,
+		filter.Kind,
+		filter.Severity,
+		filter.Status,
+		filter.limit(),
+		filter.offset(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	alerts := []*Alert{}
+
+	for rows.Next() {
+		var alert Alert
+		err := rows.Scan(
+			&totalRecords,
+			&alert.ID,
+			&alert.HotelID,
+			&alert.ProviderID,
+			&alert.Kind,
+			&alert.Severity,
+			&alert.Message,
+			&alert.Baseline,
+			&alert.Observed,
+			&alert.Score,
+			&alert.DetectedAt,
+			&alert.UpdatedAt,
+			&alert.ResolvedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filter.Page, filter.PageSize)
+
+	return alerts, metadata, nil
+}
+
+// Resolve closes an open alert. Unknown and already resolved alerts return
+// ErrRecordNotFound.
+func (m AlertModel) Resolve(ctx context.Context, id int64) error {
+	query := `UPDATE alerts SET resolved_at = now(), updated_at = now()
+	WHERE id = $1 AND resolved_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// raise opens an alert, or updates the open alert of the same kind for the
+// hotel and provider
+func (m AlertModel) raise(ctx context.Context, alert *Alert) error {
+	query := `INSERT INTO alerts (hotel_id, provider_id, kind, severity, message, baseline, observed, score)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (hotel_id, kind, (coalesce(provider_id, 0))) WHERE resolved_at IS NULL DO UPDATE SET
+		severity = EXCLUDED.severity,
+		message = EXCLUDED.message,
+		baseline = EXCLUDED.baseline,
+		observed = EXCLUDED.observed,
+		score = EXCLUDED.score,
+		updated_at = now()
+	RETURNING id, detected_at, updated_at`
+
+	args := []interface{}{
+		alert.HotelID,
+		alert.ProviderID,
+		alert.Kind,
+		alert.Severity,
+		alert.Message,
+		alert.Baseline,
+		alert.Observed,
+		alert.Score,
+	}
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&alert.ID, &alert.DetectedAt, &alert.UpdatedAt)
+}
+
+// resolveCleared resolves the open alerts of kind for the hotels that were
+// checked but are no longer anomalous. keep lists the hotel and provider
+// pairs that still are, with provider 0 for hotel wide alerts.
+func (m AlertModel) resolveCleared(ctx context.Context, kind string, hotelIDs []int64, keep map[[2]int64]bool) error {
+	var keepHotels, keepProviders []int64
+	for pair := range keep {
+		keepHotels = append(keepHotels, pair[0])
+		keepProviders = append(keepProviders, pair[1])
+	}
+
+	query := `UPDATE alerts a SET resolved_at = now(), updated_at = now()
+	WHERE a.kind = $1 AND a.resolved_at IS NULL
+	AND a.hotel_id = ANY($2)
+	AND NOT EXISTS (
+		SELECT 1 FROM unnest($3::bigint[], $4::bigint[]) AS k(hotel_id, provider_id)
+		WHERE k.hotel_id = a.hotel_id AND k.provider_id = coalesce(a.provider_id, 0)
+	)`
+
+	_, err := m.DB.ExecContext(ctx, query, kind, pq.Array(hotelIDs), pq.Array(keepHotels), pq.Array(keepProviders))
+	return err
+}
+
+// Detect checks the given hotels for rating drops and overall score drops.
+// It opens or updates an alert for each anomaly found and resolves the
+// hotels' open alerts that no longer apply. It returns the open alerts of
+// the checked hotels.
+func (m AlertModel) Detect(ctx context.Context, hotelIDs []int64, opts AnomalyOptions) ([]*Alert, error) {
+	if len(hotelIDs) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	ratingDrops, err := m.detectRatingDrops(ctx, hotelIDs, opts)
+	if err != nil {
+		return nil, fmt.Errorf("detecting rating drops: %w", err)
+	}
+
+	scoreDrops, err := m.detectScoreDrops(ctx, hotelIDs, opts)
+	if err != nil {
+		return nil, fmt.Errorf("detecting overall score drops: %w", err)
+	}
+
+	alerts := append(ratingDrops, scoreDrops...)
+	for _, alert := range alerts {
+		if err := m.raise(ctx, alert); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kind := range []string{AlertRatingDrop, AlertOverallScoreDrop} {
+		keep := make(map[[2]int64]bool)
+		for _, alert := range alerts {
+			if alert.Kind != kind {
+				continue
+			}
+			var providerID int64
+			if alert.ProviderID != nil {
+				providerID = int64(*alert.ProviderID)
+			}
+			keep[[2]int64{alert.HotelID, providerID}] = true
+		}
+
+		if err := m.resolveCleared(ctx, kind, hotelIDs, keep); err != nil {
+			return nil, err
+		}
+	}
+
+	return alerts, nil
+}
+
+// detectRatingDrops compares the mean normalized rating of each hotel's recent window
+// with its baseline. The z-score uses the standard error of the recent
+// mean, so a few bad reviews don't raise an alert but a sustained drop
+// does.
+func (m AlertModel) detectRatingDrops(ctx context.Context, hotelIDs []int64, opts AnomalyOptions) ([]*Alert, error) {
+	query := `WITH anchor AS (
+		SELECT hotel_id, max(review_date) AS latest
+		FROM reviews
+		WHERE hotel_id = ANY($1) AND review_date IS NOT NULL
+		GROUP BY hotel_id
+	)
+	SELECT a.hotel_id,
+		count(r.normalized_rating) FILTER (WHERE r.review_date > a.latest - make_interval(days => $2)),
+		coalesce(avg(r.normalized_rating) FILTER (WHERE r.review_date > a.latest - make_interval(days => $2)), 0)::float8,
+		count(r.normalized_rating) FILTER (WHERE r.review_date <= a.latest - make_interval(days => $2)),
+		coalesce(avg(r.normalized_rating) FILTER (WHERE r.review_date <= a.latest - make_interval(days => $2)), 0)::float8,
+		coalesce(stddev_samp(r.normalized_rating) FILTER (WHERE r.review_date <= a.latest - make_interval(days => $2)), 0)::float8
+	FROM anchor a
+	JOIN reviews r ON r.hotel_id = a.hotel_id
+		AND r.review_date > a.latest - make_interval(days => $2 + $3)
+	WHERE ` + countedReview + `
+	GROUP BY a.hotel_id`
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(hotelIDs), opts.RecentDays, opts.BaselineDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []*Alert{}
+	for rows.Next() {
+		var hotelID int64
+		var recentCount, baselineCount int
+		var recentMean, baselineMean, baselineSD float64
+
+		err := rows.Scan(&hotelID, &recentCount, &recentMean, &baselineCount, &baselineMean, &baselineSD)
+		if err != nil {
+			return nil, err
+		}
+
+		if recentCount < opts.MinRecent || baselineCount < opts.MinBaseline {
+			continue
+		}
+
+		z := (recentMean - baselineMean) / (math.Max(baselineSD, minStdDev) / math.Sqrt(float64(recentCount)))
+		if z > -opts.ZThreshold {
+			continue
+		}
+
+		severity := SeverityWarning
+		if z <= -opts.ZThreshold*criticalFactor {
+			severity = SeverityCritical
+		}
+
+		alerts = append(alerts, &Alert{
+			HotelID:  hotelID,
+			Kind:     AlertRatingDrop,
+			Severity: severity,
+			Message: fmt.Sprintf("mean normalized rating of the last %d days is %.1f over %d reviews, against %.1f over %d reviews in the %d days before (z=%.1f)",
+				opts.RecentDays, recentMean, recentCount, baselineMean, baselineCount, opts.BaselineDays, z),
+			Baseline: baselineMean,
+			Observed: recentMean,
+			Score:    z,
+		})
+	}
+
+	return alerts, rows.Err()
+}
+
+// detectScoreDrops finds provider overall scores that fell by at least
+// opts.ScoreDrop at their latest change, compared on the provider's
+// registered scale normalized to 0-100. A drop already covered by a
+// resolved alert, detected since the score changed, isn't raised again.
+func (m AlertModel) detectScoreDrops(ctx context.Context, hotelIDs []int64, opts AnomalyOptions) ([]*Alert, error) {
+	query := `SELECT hpr.hotel_id, hpr.provider_id, p.name,
+		hpr.previous_overall_score::float8, hpr.overall_score::float8, p.rating_scale::float8
+	FROM hotel_provider_ratings hpr
+	JOIN providers p ON p.id = hpr.provider_id
+	WHERE hpr.hotel_id = ANY($1)
+	AND (hpr.previous_overall_score - hpr.overall_score) / p.rating_scale * 100 >= $2
+	AND NOT EXISTS (
+		SELECT 1 FROM alerts a
+		WHERE a.hotel_id = hpr.hotel_id AND a.provider_id = hpr.provider_id AND a.kind = $3
+		AND a.resolved_at IS NOT NULL
+		AND (hpr.overall_score_changed_at IS NULL OR a.detected_at >= hpr.overall_score_changed_at)
+	)`
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(hotelIDs), opts.ScoreDrop, AlertOverallScoreDrop)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	alerts := []*Alert{}
+	for rows.Next() {
+		var alert Alert
+		var providerID int
+		var providerName string
+		var previous, current, scale float64
+
+		err := rows.Scan(&alert.HotelID, &providerID, &providerName, &previous, &current, &scale)
+		if err != nil {
+			return nil, err
+		}
+
+		alert.ProviderID = &providerID
+		alert.Kind = AlertOverallScoreDrop
+		alert.Baseline = math.Round(previous/scale*1000) / 10
+		alert.Observed = math.Round(current/scale*1000) / 10
+		alert.Score = alert.Baseline - alert.Observed
+		alert.Severity = SeverityWarning
+		if alert.Score >= opts.ScoreDrop*criticalFactor {
+			alert.Severity = SeverityCritical
+		}
+		alert.Message = fmt.Sprintf("%s overall score fell from %.1f to %.1f out of %g", providerName, previous, current, scale)
+
+		alerts = append(alerts, &alert)
+	}
+
+	return alerts, rows.Err()
+}
@@ -0,0 +1,184 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DateWindow limits analytics to reviews with From <= review_date < To. Nil
+// bounds are open.
+type DateWindow struct {
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+type HistogramBucket struct {
+	Rating int `json:"rating"` // Normalized ratings from Rating up to, not including, Rating+10
+	Count  int `json:"count"`
+}
+
+type Breakdown struct {
+	Key        string  `json:"key"`
+	Count      int     `json:"count"`
+	MeanRating float64 `json:"mean_rating"`
+}
+
+// HotelStats summarises a hotel's reviews within a DateWindow. Ratings are
+// normalized to 0-100 so providers with different scales can be combined.
+type HotelStats struct {
+	HotelID        int64             `json:"hotel_id"`
+	Window         DateWindow        `json:"window"`
+	Language       string            `json:"language,omitempty"` // Only reviews in this language were counted
+	ReviewCount    int               `json:"review_count"`
+	MeanRating     *float64          `json:"mean_rating"`
+	MedianRating   *float64          `json:"median_rating"`
+	Histogram      []HistogramBucket `json:"rating_histogram"`
+	ByProvider     []Breakdown       `json:"by_provider"`
+	ByCountry      []Breakdown       `json:"by_reviewer_country"`
+	ByReviewGroup  []Breakdown       `json:"by_review_group"`
+	ByLengthOfStay []Breakdown       `json:"by_length_of_stay"`
+	ByLanguage     []Breakdown       `json:"by_language"`
+	ByRoomCategory []Breakdown       `json:"by_room_category"`
+}
+
+// AnalyticsModel runs read-only aggregate queries over reviews. It is shared
+// by the API and the CLI.
+type AnalyticsModel struct {
+	DB DBTX
+}
+
+// statsWindow is the filter shared by the hotel stats queries, $1 is the
+// hotel and $2/$3 the window. Near-duplicates are counted once.
+const statsWindow = `r.hotel_id = $1
+	AND ($2::timestamp IS NULL OR r.review_date >= $2)
+	AND ($3::timestamp IS NULL OR r.review_date < $3)
+	AND ` + countedReview
+
+// statsLanguage narrows HotelStats to the detected language in $4, unless
+// it is empty
+const statsLanguage = ` AND ($4::text = '' OR r.language = $4)`
+
+// Breakdown dimensions, keyed by the SQL expression that labels each group
+const (
+	breakdownProvider    = `coalesce(p.name, '')`
+	breakdownCountry     = `coalesce(nullif(r.reviewer_country_name, ''), 'Unknown')`
+	breakdownReviewGroup = `coalesce(nullif(r.reviewer_group_name, ''), 'Unknown')`
+	breakdownStay        = `CASE
+		WHEN coalesce(r.reviewer_length_of_stay, 0) <= 0 THEN 'unknown'
+		WHEN r.reviewer_length_of_stay = 1 THEN '1 night'
+		WHEN r.reviewer_length_of_stay <= 3 THEN '2-3 nights'
+		WHEN r.reviewer_length_of_stay <= 7 THEN '4-7 nights'
+		WHEN r.reviewer_length_of_stay <= 14 THEN '8-14 nights'
+		ELSE '15+ nights'
+	END`
+	breakdownLanguage     = `coalesce(r.language, 'unknown')`
+	breakdownRoomCategory = `coalesce(rt.category, 'Unknown')`
+)
+
+// HotelStats computes review count, mean and median rating, a rating
+// histogram and breakdowns by provider, reviewer country, review group,
+// length of stay, language and room category for one hotel. A non-empty language limits
+// the figures to reviews detected in that language.
+func (a AnalyticsModel) HotelStats(ctx context.Context, hotelID int64, window DateWindow, language string) (*HotelStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stats := &HotelStats{
+		HotelID:  hotelID,
+		Window:   window,
+		Language: language,
+	}
+	args := []interface{}{hotelID, window.From, window.To, language}
+
+	query := `SELECT count(*),
+		avg(r.normalized_rating)::float8,
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY r.normalized_rating)::float8
+	FROM reviews r
+	WHERE ` + statsWindow + statsLanguage
+
+	err := a.DB.QueryRowContext(ctx, query, args...).Scan(&stats.ReviewCount, &stats.MeanRating, &stats.MedianRating)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Histogram, err = a.ratingHistogram(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdowns := []struct {
+		expr   string
+		target *[]Breakdown
+	}{
+		{breakdownProvider, &stats.ByProvider},
+		{breakdownCountry, &stats.ByCountry},
+		{breakdownReviewGroup, &stats.ByReviewGroup},
+		{breakdownStay, &stats.ByLengthOfStay},
+		{breakdownLanguage, &stats.ByLanguage},
+		{breakdownRoomCategory, &stats.ByRoomCategory},
+	}
+
+	for _, b := range breakdowns {
+		*b.target, err = a.breakdown(ctx, b.expr, args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+func (a AnalyticsModel) ratingHistogram(ctx context.Context, args []interface{}) ([]HistogramBucket, error) {
+	query := `SELECT floor(r.normalized_rating / 10)::int * 10 AS bucket, count(*)
+	FROM reviews r
+	WHERE ` + statsWindow + statsLanguage + ` AND r.normalized_rating IS NOT NULL
+	GROUP BY bucket
+	ORDER BY bucket`
+
+	rows, err := a.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []HistogramBucket{}
+	for rows.Next() {
+		var bucket HistogramBucket
+		if err := rows.Scan(&bucket.Rating, &bucket.Count); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// breakdown groups the window's reviews by expr, which must be one of the
+// breakdown constants
+func (a AnalyticsModel) breakdown(ctx context.Context, expr string, args []interface{}) ([]Breakdown, error) {
+	query := fmt.Sprintf(`SELECT %s AS key, count(*), coalesce(avg(r.normalized_rating), 0)::float8
+	FROM reviews r
+	LEFT JOIN providers p ON p.id = r.provider_id
+	LEFT JOIN room_types rt ON rt.id = r.room_type_id
+	WHERE %s
+	GROUP BY key
+	ORDER BY count(*) DESC, key`, expr, statsWindow+statsLanguage)
+
+	rows, err := a.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdowns := []Breakdown{}
+	for rows.Next() {
+		var b Breakdown
+		if err := rows.Scan(&b.Key, &b.Count, &b.MeanRating); err != nil {
+			return nil, err
+		}
+		breakdowns = append(breakdowns, b)
+	}
+
+	return breakdowns, rows.Err()
+}
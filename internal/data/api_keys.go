@@ -0,0 +1,324 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// API key scopes
+const (
+	ScopeReviewsRead = "reviews:read"
+	ScopeIngestAdmin = "ingest:admin"
+)
+
+var AllScopes = []string{ScopeReviewsRead, ScopeIngestAdmin}
+
+// apiKeyPrefix marks review-system keys so they are easy to spot in logs
+// and secret scanners
+const apiKeyPrefix = "rvk_"
+
+// APIKey is an API client credential. Only the SHA-256 hash of the key is
+// stored; Plaintext is set once, when the key is created.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Plaintext  string     `json:"key,omitempty"`
+	Hash       []byte     `json:"-"`
+	Prefix     string     `json:"prefix"`
+	Owner      string     `json:"owner"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	Limits     RateLimits `json:"limits"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// RateLimits override the server's default limits for one key. Nil fields
+// fall back to the default; a DailyQuota of 0 exempts the key from the
+// default quota.
+type RateLimits struct {
+	RPS        *float64 `json:"rps,omitempty"`
+	Burst      *int     `json:"burst,omitempty"`
+	DailyQuota *int     `json:"daily_quota,omitempty"`
+}
+
+// EffectiveRateLimits are the limits a request is checked against
+type EffectiveRateLimits struct {
+	RPS        float64
+	Burst      int
+	DailyQuota int // 0 for no quota
+}
+
+// Or returns the limits in defaults overridden by the fields set in l
+func (l RateLimits) Or(defaults EffectiveRateLimits) EffectiveRateLimits {
+	if l.RPS != nil {
+		defaults.RPS = *l.RPS
+	}
+	if l.Burst != nil {
+		defaults.Burst = *l.Burst
+	}
+	if l.DailyQuota != nil {
+		defaults.DailyQuota = *l.DailyQuota
+	}
+	return defaults
+}
+
+func ValidateRateLimits(v *validator.Validator, limits RateLimits) {
+	if limits.RPS != nil {
+		v.Check(*limits.RPS > 0, "rps", "must be greater than zero")
+	}
+	if limits.Burst != nil {
+		v.Check(*limits.Burst > 0, "burst", "must be greater than zero")
+	}
+	if limits.DailyQuota != nil {
+		v.Check(*limits.DailyQuota >= 0, "daily_quota", "must not be negative")
+	}
+}
+
+// HasScope reports whether the key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func ValidateAPIKey(v *validator.Validator, key *APIKey) {
+	v.Check(key.Owner != "", "owner", "must be provided")
+	v.Check(len(key.Owner) <= 200, "owner", "must not be more than 200 bytes long")
+	v.Check(len(key.Scopes) > 0, "scopes", "must contain at least one scope")
+
+	for _, scope := range key.Scopes {
+		v.Check(validator.PermittedValue(scope, AllScopes...), "scopes", "contains an unknown scope: "+scope)
+	}
+
+	if key.ExpiresAt != nil {
+		v.Check(key.ExpiresAt.After(time.Now()), "expires_at", "must be in the future")
+	}
+
+	ValidateRateLimits(v, key.Limits)
+}
+
+// HashAPIKey returns the SHA-256 hash stored for a plaintext key
+func HashAPIKey(plaintext string) []byte {
+	hash := sha256.Sum256([]byte(plaintext))
+	return hash[:]
+}
+
+// generateAPIKey builds a key for owner with a random plaintext
+func generateAPIKey(owner string, scopes []string, ttl time.Duration, limits RateLimits) (*APIKey, error) {
+	randomBytes := make([]byte, 20)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+
+	plaintext := apiKeyPrefix + base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)
+
+	key := &APIKey{
+		Plaintext: plaintext,
+		Hash:      HashAPIKey(plaintext),
+		Prefix:    plaintext[:len(apiKeyPrefix)+6],
+		Owner:     owner,
+		Scopes:    scopes,
+		Limits:    limits,
+	}
+
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	return key, nil
+}
+
+type APIKeyModel struct {
+	DB DBTX
+}
+
+// New generates and stores a key. ttl <= 0 creates a key that never expires.
+func (m APIKeyModel) New(owner string, scopes []string, ttl time.Duration, limits RateLimits) (*APIKey, error) {
+	key, err := generateAPIKey(owner, scopes, ttl, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New()
+	if ValidateAPIKey(v, key); !v.Valid() {
+		return nil, fmt.Errorf("invalid api key: %v", v.Errors)
+	}
+
+	err = m.Insert(key)
+	return key, err
+}
+
+func (m APIKeyModel) Insert(key *APIKey) error {
+	query := `INSERT INTO api_keys (key_hash, prefix, owner, scopes, expires_at, rate_limit_rps, rate_limit_burst, daily_quota)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at`
+
+	args := []interface{}{
+		key.Hash,
+		key.Prefix,
+		key.Owner,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+		key.Limits.RPS,
+		key.Limits.Burst,
+		key.Limits.DailyQuota,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetForKey returns the active key matching a plaintext key. Unknown,
+// revoked and expired keys return ErrRecordNotFound.
+func (m APIKeyModel) GetForKey(ctx context.Context, plaintext string) (*APIKey, error) {
+	query := `SELECT id, prefix, owner, scopes, expires_at, last_used_at, revoked_at,
+		rate_limit_rps, rate_limit_burst, daily_quota, created_at
+	FROM api_keys
+	WHERE key_hash = $1
+	AND revoked_at IS NULL
+	AND (expires_at IS NULL OR expires_at > now())`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	key := &APIKey{Hash: HashAPIKey(plaintext)}
+	err := m.DB.QueryRowContext(ctx, query, key.Hash).Scan(
+		&key.ID,
+		&key.Prefix,
+		&key.Owner,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.Limits.RPS,
+		&key.Limits.Burst,
+		&key.Limits.DailyQuota,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
+
+// TouchLastUsed records that the key was used. Writes are limited to one a
+// minute per key so busy clients don't cause a write per request.
+func (m APIKeyModel) TouchLastUsed(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET last_used_at = now()
+	WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetAll returns every key, newest first, without hashes
+func (m APIKeyModel) GetAll(ctx context.Context) ([]*APIKey, error) {
+	query := `SELECT id, prefix, owner, scopes, expires_at, last_used_at, revoked_at,
+		rate_limit_rps, rate_limit_burst, daily_quota, created_at
+	FROM api_keys
+	ORDER BY id DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		var key APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.Prefix,
+			&key.Owner,
+			pq.Array(&key.Scopes),
+			&key.ExpiresAt,
+			&key.LastUsedAt,
+			&key.RevokedAt,
+			&key.Limits.RPS,
+			&key.Limits.Burst,
+			&key.Limits.DailyQuota,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke disables a key. Revoking an unknown or already revoked key returns
+// ErrRecordNotFound.
+func (m APIKeyModel) Revoke(ctx context.Context, id int64) error {
+	query := `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// SetLimits replaces the rate limits of an active key. Nil fields reset a
+// limit to the server default.
+func (m APIKeyModel) SetLimits(ctx context.Context, id int64, limits RateLimits) error {
+	query := `UPDATE api_keys
+	SET rate_limit_rps = $2, rate_limit_burst = $3, daily_quota = $4
+	WHERE id = $1 AND revoked_at IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, limits.RPS, limits.Burst, limits.DailyQuota)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
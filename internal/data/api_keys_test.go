@@ -0,0 +1,58 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func TestRateLimitsOr(t *testing.T) {
+	defaults := EffectiveRateLimits{RPS: 10, Burst: 20, DailyQuota: 1000}
+	rps, burst, quota, noQuota := 2.5, 5, 50, 0
+
+	tests := []struct {
+		name   string
+		limits RateLimits
+		want   EffectiveRateLimits
+	}{
+		{"unset", RateLimits{}, defaults},
+		{"all set", RateLimits{RPS: &rps, Burst: &burst, DailyQuota: &quota}, EffectiveRateLimits{RPS: 2.5, Burst: 5, DailyQuota: 50}},
+		{"some set", RateLimits{Burst: &burst}, EffectiveRateLimits{RPS: 10, Burst: 5, DailyQuota: 1000}},
+		{"quota opt-out", RateLimits{DailyQuota: &noQuota}, EffectiveRateLimits{RPS: 10, Burst: 20, DailyQuota: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.Or(defaults); got != tt.want {
+				t.Errorf("Or() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRateLimits(t *testing.T) {
+	positive, zero, negative := 1, 0, -1
+	zeroRPS := 0.0
+
+	tests := []struct {
+		name   string
+		limits RateLimits
+		valid  bool
+	}{
+		{"unset", RateLimits{}, true},
+		{"set", RateLimits{Burst: &positive, DailyQuota: &positive}, true},
+		{"no quota", RateLimits{DailyQuota: &zero}, true},
+		{"negative quota", RateLimits{DailyQuota: &negative}, false},
+		{"zero burst", RateLimits{Burst: &zero}, false},
+		{"zero rps", RateLimits{RPS: &zeroRPS}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			if ValidateRateLimits(v, tt.limits); v.Valid() != tt.valid {
+				t.Errorf("valid = %v, want %v: %v", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
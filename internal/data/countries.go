@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -38,3 +39,48 @@ func (c CountryModel) CreateOrGet(name, flag string) (*Country, error) {
 	err = c.DB.QueryRowContext(ctx, insertQuery, name, flag).Scan(&country.ID, &country.Name, &country.Flag, &country.CreatedAt)
 	return country, err
 }
+
+// Resolve returns the country a platform sends as externalID, name and
+// flag. The external id is matched first, following a renamed country;
+// without a mapping the country is looked up by name, including the names
+// platforms renamed it to, and the id mapped onto it. An externalID of 0
+// means the feed sent none.
+func (c CountryModel) Resolve(platform string, externalID int, name, flag string) (*Country, error) {
+	if externalID != 0 {
+		id, err := countryExternalIDs.resolve(c.DB, platform, externalID, name)
+		switch {
+		case err == nil:
+			return c.get(id)
+		case !errors.Is(err, ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	var country *Country
+	id, err := countryExternalIDs.byName(c.DB, name)
+	switch {
+	case err == nil:
+		country, err = c.get(id)
+	case errors.Is(err, ErrRecordNotFound):
+		country, err = c.CreateOrGet(name, flag)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if externalID == 0 {
+		return country, nil
+	}
+
+	return country, countryExternalIDs.add(c.DB, platform, externalID, country.ID, name)
+}
+
+func (c CountryModel) get(id int) (*Country, error) {
+	query := `SELECT id, name, flag, created_at FROM countries WHERE id = $1`
+	country := &Country{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := c.DB.QueryRowContext(ctx, query, id).Scan(&country.ID, &country.Name, &country.Flag, &country.CreatedAt)
+	return country, err
+}
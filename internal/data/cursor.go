@@ -0,0 +1,38 @@
+package data
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a keyset-paginated page. Value is the sort
+// column of that row in its Postgres text form, ID breaks ties.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+// Encode returns the opaque token handed to clients
+func (c Cursor) Encode() string {
+	js, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode
+func DecodeCursor(token string) (*Cursor, error) {
+	js, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(js, &c); err != nil || c.Sort == "" || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,136 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DailyStatsKey identifies the reviews of one hotel on one review day
+type DailyStatsKey struct {
+	HotelID int64
+	Day     time.Time
+}
+
+// DailyStatsModel maintains hotel_daily_stats, the per hotel, provider and
+// day rollup of reviews. Near-duplicates are counted once.
+type DailyStatsModel struct {
+	DB DBTX
+}
+
+// dailyHistogram counts reviews by floor(normalized_rating / 10), 0-10
+var dailyHistogram = func() string {
+	buckets := make([]string, 11)
+	for i := range buckets {
+		buckets[i] = fmt.Sprintf("count(*) FILTER (WHERE floor(r.normalized_rating / 10)::int = %d)", i)
+	}
+	return "ARRAY[" + strings.Join(buckets, ", ") + "]::integer[]"
+}()
+
+// dailyStatsColumns aggregates reviews r grouped by hotel, provider and day
+var dailyStatsColumns = `r.hotel_id, r.provider_id, r.review_date::date AS day,
+		count(*) AS review_count,
+		coalesce(sum(r.normalized_rating), 0) AS normalized_rating_sum,
+		` + dailyHistogram + ` AS rating_histogram,
+		count(*) FILTER (WHERE coalesce(r.responder_name, '') <> '') AS response_count`
+
+// lockHotels takes a transaction level advisory lock per hotel, in hotel
+// order so concurrent callers can't deadlock
+func (m DailyStatsModel) lockHotels(ctx context.Context, hotelIDs []int64) error {
+	query := `SELECT count(pg_advisory_xact_lock(hashtextextended('hotel_daily_stats', 0) # h.hotel_id))
+	FROM (SELECT DISTINCT unnest($1::bigint[]) AS hotel_id ORDER BY 1) h`
+
+	var locked int
+	return m.DB.QueryRowContext(ctx, query, pq.Array(hotelIDs)).Scan(&locked)
+}
+
+// Refresh recomputes the rollup rows of the given hotel days from reviews,
+// removing rows whose reviews are gone. It must run in a transaction: the
+// hotels are locked until it commits, so two importers refreshing the same
+// hotel can't overwrite each other's counts with stale ones.
+func (m DailyStatsModel) Refresh(ctx context.Context, keys []DailyStatsKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	hotelIDs := make([]int64, len(keys))
+	days := make([]string, len(keys))
+	for i, key := range keys {
+		hotelIDs[i] = key.HotelID
+		days[i] = key.Day.Format(time.DateOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := m.lockHotels(ctx, hotelIDs); err != nil {
+		return err
+	}
+
+	query := `WITH touched AS (
+		SELECT DISTINCT hotel_id, day FROM unnest($1::bigint[], $2::date[]) AS t(hotel_id, day)
+	),
+	fresh AS (
+		SELECT ` + dailyStatsColumns + `
+		FROM reviews r
+		JOIN touched t ON t.hotel_id = r.hotel_id AND r.review_date >= t.day AND r.review_date < t.day + 1
+		WHERE ` + countedReview + `
+		GROUP BY r.hotel_id, r.provider_id, r.review_date::date
+	),
+	upserted AS (
+		INSERT INTO hotel_daily_stats (hotel_id, provider_id, day, review_count, normalized_rating_sum, rating_histogram, response_count)
+		SELECT hotel_id, provider_id, day, review_count, normalized_rating_sum, rating_histogram, response_count FROM fresh
+		ON CONFLICT (hotel_id, provider_id, day) DO UPDATE SET
+			review_count = EXCLUDED.review_count,
+			normalized_rating_sum = EXCLUDED.normalized_rating_sum,
+			rating_histogram = EXCLUDED.rating_histogram,
+			response_count = EXCLUDED.response_count,
+			updated_at = now()
+	)
+	DELETE FROM hotel_daily_stats d
+	USING touched t
+	WHERE d.hotel_id = t.hotel_id AND d.day = t.day
+	AND NOT EXISTS (SELECT 1 FROM fresh f WHERE f.hotel_id = d.hotel_id AND f.provider_id = d.provider_id AND f.day = d.day)`
+
+	_, err := m.DB.ExecContext(ctx, query, pq.Array(hotelIDs), pq.Array(days))
+	return err
+}
+
+// Rebuild recomputes the rollup from scratch, for every hotel or only for
+// hotelID when it is not nil. It must run in a transaction, and returns the
+// number of rows written.
+func (m DailyStatsModel) Rebuild(ctx context.Context, hotelID *int64) (int64, error) {
+	if hotelID != nil {
+		if err := m.lockHotels(ctx, []int64{*hotelID}); err != nil {
+			return 0, err
+		}
+	} else {
+		// Block incremental refreshes until the rebuild commits
+		if _, err := m.DB.ExecContext(ctx, `LOCK TABLE hotel_daily_stats IN EXCLUSIVE MODE`); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM hotel_daily_stats WHERE $1::bigint IS NULL OR hotel_id = $1`, hotelID)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO hotel_daily_stats (hotel_id, provider_id, day, review_count, normalized_rating_sum, rating_histogram, response_count)
+	SELECT ` + dailyStatsColumns + `
+	FROM reviews r
+	WHERE r.review_date IS NOT NULL
+	AND ($1::bigint IS NULL OR r.hotel_id = $1)
+	AND ` + countedReview + `
+	GROUP BY r.hotel_id, r.provider_id, r.review_date::date`
+
+	result, err := m.DB.ExecContext(ctx, query, hotelID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,236 @@
+// Package datatest provides a scripted database/sql driver, so code that
+// runs its queries through data.Models can be tested without a Postgres
+// server. A Handler answers each statement; transactions always commit.
+package datatest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Result is the answer to one statement. Queries return Rows under
+// Columns, Execs report RowsAffected; a non-nil Err fails the statement.
+type Result struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	Err          error
+}
+
+// Handler answers a statement with its arguments. It is called from any
+// goroutine running a query.
+type Handler func(query string, args []driver.Value) Result
+
+// Statement is a statement the DB ran
+type Statement struct {
+	Query string
+	Args  []driver.Value
+}
+
+// DB records the statements run through it
+type DB struct {
+	handler Handler
+
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// Open returns a *sql.DB whose statements are answered by handler, and the
+// DB recording them. A nil handler answers every statement with no rows.
+func Open(handler Handler) (*sql.DB, *DB) {
+	if handler == nil {
+		handler = func(string, []driver.Value) Result { return Result{} }
+	}
+	db := &DB{handler: handler}
+	return sql.OpenDB(connector{db}), db
+}
+
+// Statements returns the statements run so far, in order
+func (db *DB) Statements() []Statement {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]Statement(nil), db.statements...)
+}
+
+// Ran reports whether a statement containing each of parts was run
+func (db *DB) Ran(parts ...string) bool {
+	for _, s := range db.Statements() {
+		if containsAll(s.Query, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns a Handler that answers the first statement containing each
+// of a case's parts with its Result, and others with no rows
+func Match(cases ...Case) Handler {
+	return func(query string, args []driver.Value) Result {
+		for _, c := range cases {
+			if containsAll(query, c.Parts) {
+				return c.Result
+			}
+		}
+		return Result{}
+	}
+}
+
+// Case answers statements containing each of Parts with Result
+type Case struct {
+	Parts  []string
+	Result Result
+}
+
+func containsAll(query string, parts []string) bool {
+	for _, part := range parts {
+		if !strings.Contains(query, part) {
+			return false
+		}
+	}
+	return true
+}
+
+func (db *DB) run(query string, named []driver.NamedValue) Result {
+	args := make([]driver.Value, len(named))
+	for i, nv := range named {
+		args[i] = nv.Value
+	}
+
+	db.mu.Lock()
+	db.statements = append(db.statements, Statement{Query: query, Args: args})
+	db.mu.Unlock()
+
+	return db.handler(query, args)
+}
+
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{c.db}
+}
+
+type fakeDriver struct {
+	db *DB
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return &conn{db: d.db}, nil
+}
+
+type conn struct {
+	db *DB
+}
+
+// CheckNamedValue accepts every argument as is, e.g. pq arrays
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if valuer, ok := nv.Value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		nv.Value = v
+		return err
+	}
+	return nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.run("BEGIN", nil)
+	return tx{c}, nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return &rows{columns: result.Columns, values: result.Rows}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := c.db.run(query, args)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t tx) Commit() error {
+	t.conn.db.run("COMMIT", nil)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.conn.db.run("ROLLBACK", nil)
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("datatest: use ExecContext")
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("datatest: use QueryContext")
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	next    int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
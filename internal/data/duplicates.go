@@ -0,0 +1,244 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahesh-singh/review-system/internal/dedupe"
+)
+
+// countedReview keeps one review per near-duplicate cluster in analytics:
+// the review the cluster is named after, or any review not yet clustered
+const countedReview = `(r.cluster_id IS NULL OR r.cluster_id = r.id)`
+
+// ReviewSimhash is the text signature of one review, nil for texts too
+// short to sign
+type ReviewSimhash struct {
+	ID      int64
+	Simhash *int64
+}
+
+// ListUnsigned returns up to limit reviews after afterID, in id order, whose
+// text was not signed by version. With all set every review is returned.
+func (r ReviewModel) ListUnsigned(ctx context.Context, version int, all bool, afterID int64, limit int) ([]*ReviewText, error) {
+	query := `SELECT id, coalesce(review_positives, ''), coalesce(review_negatives, ''), coalesce(review_comments, '')
+	FROM reviews
+	WHERE id > $1
+	AND ($2::boolean OR simhash_version IS DISTINCT FROM $3)
+	ORDER BY id
+	LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, afterID, all, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := []*ReviewText{}
+	for rows.Next() {
+		var text ReviewText
+		if err := rows.Scan(&text.ID, &text.Positives, &text.Negatives, &text.Comments); err != nil {
+			return nil, err
+		}
+		texts = append(texts, &text)
+	}
+
+	return texts, rows.Err()
+}
+
+// SetSimhashes stores the signatures computed by version and returns the
+// number of reviews updated. Clusters are not touched; recluster the
+// hotels afterwards.
+func (r ReviewModel) SetSimhashes(ctx context.Context, version int, signatures []ReviewSimhash) (int64, error) {
+	if len(signatures) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(signatures))
+	values := make([]sql.NullInt64, len(signatures))
+	for i, signature := range signatures {
+		ids[i] = signature.ID
+		if signature.Simhash != nil {
+			values[i] = sql.NullInt64{Int64: *signature.Simhash, Valid: true}
+		}
+	}
+
+	query := `UPDATE reviews r
+	SET text_simhash = s.simhash, simhash_version = $3
+	FROM unnest($1::bigint[], $2::bigint[]) AS s(id, simhash)
+	WHERE r.id = s.id`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, pq.Array(ids), pq.GenericArray{A: values}, version)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DuplicateModel clusters near-duplicate reviews
+type DuplicateModel struct {
+	DB DBTX
+}
+
+// Recluster recomputes the near-duplicate clusters of a hotel from the
+// stored signatures and returns the hotel days of the reviews whose
+// cluster changed, whose rollup rows need refreshing. Reviews without a
+// signature are left unclustered. Only the reviews whose cluster changed
+// are written, with updated_at bumped so cached analytics are revalidated,
+// so reclustering a large hotel after an import costs one read of its
+// signatures.
+//
+// It must run in a transaction: the hotel is locked until it commits, so
+// two reclusters of the same hotel run one after the other. Reviews
+// committed meanwhile keep their cluster until the next recluster.
+func (m DuplicateModel) Recluster(ctx context.Context, hotelID int64, opts dedupe.Options) ([]DailyStatsKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if err := (DailyStatsModel{DB: m.DB}).lockHotels(ctx, []int64{hotelID}); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, text_simhash, coalesce(reviewer_display_name, ''), review_date, cluster_id
+	FROM reviews
+	WHERE hotel_id = $1`
+
+	rows, err := m.DB.QueryContext(ctx, query, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	current := make(map[int64]sql.NullInt64)
+	items := []dedupe.Item{}
+	for rows.Next() {
+		var item dedupe.Item
+		var signature, clusterID sql.NullInt64
+		var name string
+		var date sql.NullTime
+		if err := rows.Scan(&item.ID, &signature, &name, &date, &clusterID); err != nil {
+			return nil, err
+		}
+		current[item.ID] = clusterID
+		if !signature.Valid {
+			continue
+		}
+		item.Signature = uint64(signature.Int64)
+		item.Reviewer = dedupe.ReviewerKey(name)
+		item.Date = date.Time
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	clusters := dedupe.Cluster(items, opts)
+	ids := []int64{}
+	clusterIDs := []sql.NullInt64{}
+	for id, was := range current {
+		clusterID, ok := clusters[id]
+		now := sql.NullInt64{Int64: clusterID, Valid: ok}
+		if now != was {
+			ids = append(ids, id)
+			clusterIDs = append(clusterIDs, now)
+		}
+	}
+	if len(ids) == 0 {
+		return []DailyStatsKey{}, nil
+	}
+
+	update := `UPDATE reviews r
+	SET cluster_id = c.cluster_id, updated_at = CURRENT_TIMESTAMP
+	FROM unnest($2::bigint[], $3::bigint[]) AS c(id, cluster_id)
+	WHERE r.id = c.id AND r.hotel_id = $1 AND r.cluster_id IS DISTINCT FROM c.cluster_id
+	RETURNING r.review_date`
+
+	changed, err := m.DB.QueryContext(ctx, update, hotelID, pq.Array(ids), pq.GenericArray{A: clusterIDs})
+	if err != nil {
+		return nil, err
+	}
+	defer changed.Close()
+
+	days := make(map[time.Time]struct{})
+	for changed.Next() {
+		var date sql.NullTime
+		if err := changed.Scan(&date); err != nil {
+			return nil, err
+		}
+		if date.Valid {
+			y, mo, d := date.Time.Date()
+			days[time.Date(y, mo, d, 0, 0, 0, 0, time.UTC)] = struct{}{}
+		}
+	}
+	if err := changed.Err(); err != nil {
+		return nil, err
+	}
+
+	keys := make([]DailyStatsKey, 0, len(days))
+	for day := range days {
+		keys = append(keys, DailyStatsKey{HotelID: hotelID, Day: day})
+	}
+
+	return keys, nil
+}
+
+// ProviderDuplication is the share of a provider's reviews that duplicate
+// a review counted elsewhere
+type ProviderDuplication struct {
+	ProviderID      int     `json:"provider_id"`
+	ProviderName    string  `json:"provider_name"`
+	ReviewCount     int     `json:"review_count"`
+	Duplicates      int     `json:"duplicates"`     // Reviews in a cluster named after another review
+	CrossProvider   int     `json:"cross_provider"` // Duplicates of another provider's review
+	DuplicationRate float64 `json:"duplication_rate"`
+}
+
+// DuplicationByProvider reports the duplication rate of each provider, over
+// every hotel or only hotelID, for reviews within the window
+func (a AnalyticsModel) DuplicationByProvider(ctx context.Context, hotelID *int64, window DateWindow) ([]ProviderDuplication, error) {
+	query := `SELECT p.id, p.name, count(*),
+		count(*) FILTER (WHERE r.cluster_id <> r.id),
+		count(*) FILTER (WHERE rep.provider_id <> r.provider_id)
+	FROM reviews r
+	JOIN providers p ON p.id = r.provider_id
+	LEFT JOIN reviews rep ON rep.id = r.cluster_id AND r.cluster_id <> r.id
+	WHERE ($1::bigint IS NULL OR r.hotel_id = $1)
+	AND ($2::timestamp IS NULL OR r.review_date >= $2)
+	AND ($3::timestamp IS NULL OR r.review_date < $3)
+	GROUP BY p.id, p.name
+	ORDER BY p.name`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, hotelID, window.From, window.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := []ProviderDuplication{}
+	for rows.Next() {
+		var d ProviderDuplication
+		err := rows.Scan(&d.ProviderID, &d.ProviderName, &d.ReviewCount, &d.Duplicates, &d.CrossProvider)
+		if err != nil {
+			return nil, err
+		}
+		if d.ReviewCount > 0 {
+			d.DuplicationRate = math.Round(float64(d.Duplicates)/float64(d.ReviewCount)*1000) / 1000
+		}
+		report = append(report, d)
+	}
+
+	return report, rows.Err()
+}
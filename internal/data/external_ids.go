@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Lookups whose names are tracked against a platform's external ids
+const (
+	LookupProvider    = "provider"
+	LookupCountry     = "country"
+	LookupReviewGroup = "review_group"
+	LookupRoomType    = "room_type"
+)
+
+// externalIDTable maps one platform's ids of a lookup onto its rows
+type externalIDTable struct {
+	lookup string // Lookup kind, as recorded in lookup_name_changes
+	table  string // Mapping table
+	column string // Mapping column referencing the lookup row
+	rows   string // Lookup table
+}
+
+var (
+	providerExternalIDs    = externalIDTable{LookupProvider, "provider_external_ids", "provider_id", "providers"}
+	countryExternalIDs     = externalIDTable{LookupCountry, "country_external_ids", "country_id", "countries"}
+	reviewGroupExternalIDs = externalIDTable{LookupReviewGroup, "review_group_external_ids", "review_group_id", "review_groups"}
+)
+
+// resolve returns the id of the lookup row externalID maps to on platform,
+// or ErrRecordNotFound when the id is not mapped yet. When the platform now
+// sends another name the change is recorded and the mapping takes the new
+// name. The lookup row keeps its name, as other platforms may still send it;
+// the new name resolves to the row through the mapping, see byName.
+func (t externalIDTable) resolve(db DBTX, platform string, externalID int, name string) (int, error) {
+	query := fmt.Sprintf(`SELECT %s, name FROM %s WHERE platform = $1 AND external_id = $2`, t.column, t.table)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var lookupID int
+	var oldName string
+	err := db.QueryRowContext(ctx, query, platform, externalID).Scan(&lookupID, &oldName)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	if name == "" || name == oldName {
+		return lookupID, nil
+	}
+
+	query = fmt.Sprintf(`UPDATE %s SET name = $3, updated_at = CURRENT_TIMESTAMP
+	WHERE platform = $1 AND external_id = $2`, t.table)
+	if _, err := db.ExecContext(ctx, query, platform, externalID, name); err != nil {
+		return 0, err
+	}
+
+	err = recordNameChange(ctx, db, LookupNameChange{
+		Lookup:     t.lookup,
+		LookupID:   lookupID,
+		Platform:   platform,
+		ExternalID: externalID,
+		OldName:    oldName,
+		NewName:    name,
+	})
+	return lookupID, err
+}
+
+// byName returns the id of the lookup row called name, or of the row a
+// platform maps an id of that name onto, or ErrRecordNotFound. A row's own
+// name wins, so a name a platform renamed one row to can't hide another.
+func (t externalIDTable) byName(db DBTX, name string) (int, error) {
+	query := fmt.Sprintf(`SELECT id FROM (
+		SELECT id, 0 AS rank FROM %[1]s WHERE name = $1
+		UNION ALL
+		SELECT %[2]s, 1 FROM %[3]s WHERE name = $1
+	) named
+	ORDER BY rank
+	LIMIT 1`, t.rows, t.column, t.table)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var lookupID int
+	err := db.QueryRowContext(ctx, query, name).Scan(&lookupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return lookupID, nil
+}
+
+// add maps externalID on platform onto the lookup row lookupID. An id that
+// is already mapped keeps its mapping.
+func (t externalIDTable) add(db DBTX, platform string, externalID, lookupID int, name string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (platform, external_id, %s, name) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (platform, external_id) DO NOTHING`, t.table, t.column)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctx, query, platform, externalID, lookupID, name)
+	return err
+}
+
+// LookupNameChange is a name a platform changed for one of its external ids
+type LookupNameChange struct {
+	ID         int64     `json:"id"`
+	Lookup     string    `json:"lookup"`
+	LookupID   int       `json:"lookup_id"`
+	Platform   string    `json:"platform"`
+	ExternalID int       `json:"external_id"`
+	OldName    string    `json:"old_name"`
+	NewName    string    `json:"new_name"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+func recordNameChange(ctx context.Context, db DBTX, change LookupNameChange) error {
+	query := `INSERT INTO lookup_name_changes (lookup, lookup_id, platform, external_id, old_name, new_name)
+	VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := db.ExecContext(ctx, query,
+		change.Lookup, change.LookupID, change.Platform, change.ExternalID, change.OldName, change.NewName)
+	return err
+}
+
+// LookupModel reads the name history of the lookups
+type LookupModel struct {
+	DB DBTX
+}
+
+// NameChanges returns the recorded name changes, newest first, of one kind
+// of lookup or of all of them when lookup is empty
+func (m LookupModel) NameChanges(ctx context.Context, lookup string) ([]*LookupNameChange, error) {
+	query := `SELECT id, lookup, lookup_id, platform, external_id, old_name, new_name, changed_at
+	FROM lookup_name_changes
+	WHERE $1::text = '' OR lookup = $1
+	ORDER BY changed_at DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, lookup)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*LookupNameChange{}
+	for rows.Next() {
+		var change LookupNameChange
+		err := rows.Scan(
+			&change.ID,
+			&change.Lookup,
+			&change.LookupID,
+			&change.Platform,
+			&change.ExternalID,
+			&change.OldName,
+			&change.NewName,
+			&change.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
@@ -0,0 +1,271 @@
+package data
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Grade categories of hotel_provider_ratings, in report order
+var GradeCategories = []string{
+	"overall",
+	"cleanliness",
+	"facilities",
+	"location",
+	"room_comfort_quality",
+	"service",
+	"value_for_money",
+}
+
+type GradeComparisonOptions struct {
+	Threshold       float64            // Spread on the 0-100 scale above which providers disagree
+	Scales          map[string]float64 // Maximum grade per provider name, overriding the registered rating scale
+	PlatformAverage bool               // Also compare against the platform-wide averages
+}
+
+// ProviderGrades are one provider's grades for a hotel normalised to 0-100.
+// Missing grades are nil.
+type ProviderGrades struct {
+	ProviderID   int                 `json:"provider_id"`
+	ProviderName string              `json:"provider_name"`
+	Scale        float64             `json:"scale"`
+	ReviewCount  int                 `json:"review_count"`
+	Grades       map[string]*float64 `json:"grades"`
+}
+
+// CategoryComparison puts the providers' normalised grades for one category
+// side by side
+type CategoryComparison struct {
+	Category          string              `json:"category"`
+	Scores            map[string]*float64 `json:"scores"`
+	Min               *float64            `json:"min"`
+	Max               *float64            `json:"max"`
+	Spread            *float64            `json:"spread"`
+	Disagreement      bool                `json:"disagreement"`
+	PlatformAverage   *float64            `json:"platform_average,omitempty"`
+	DeltaFromPlatform *float64            `json:"delta_from_platform,omitempty"`
+}
+
+type GradeComparison struct {
+	HotelID    int64                `json:"hotel_id"`
+	Platform   string               `json:"platform"`
+	Threshold  float64              `json:"threshold"`
+	Providers  []ProviderGrades     `json:"providers"`
+	Categories []CategoryComparison `json:"categories"`
+}
+
+// rawGrades holds grades in provider scale, keyed by category
+type rawGrades map[string]*float64
+
+func gradesOf(rating *HotelProviderRating) rawGrades {
+	overall := rating.OverallScore
+	return rawGrades{
+		"overall":              &overall,
+		"cleanliness":          rating.Cleanliness,
+		"facilities":           rating.Facilities,
+		"location":             rating.Location,
+		"room_comfort_quality": rating.RoomComfortQuality,
+		"service":              rating.Service,
+		"value_for_money":      rating.ValueForMoney,
+	}
+}
+
+// normaliseGrade maps a grade to 0-100. The importer stores absent grades as
+// zero, so zero is treated as missing.
+func normaliseGrade(grade *float64, scale float64) *float64 {
+	if grade == nil || *grade <= 0 || scale <= 0 {
+		return nil
+	}
+	n := math.Round(*grade/scale*1000) / 10
+	return &n
+}
+
+// scale returns the grade scale of a provider: the override in o.Scales
+// for its name, else its registered rating scale. registered is keyed by
+// provider id, as feeds may rename a provider without changing its id.
+func (o GradeComparisonOptions) scale(providerID int, providerName string, registered map[int]float64) float64 {
+	if scale, ok := o.Scales[providerName]; ok && scale > 0 {
+		return scale
+	}
+	if scale, ok := registered[providerID]; ok && scale > 0 {
+		return scale
+	}
+	return DefaultRatingScale
+}
+
+// providerGrades normalises a provider's grades for a hotel on its scale
+func providerGrades(rating *HotelProviderRating, opts GradeComparisonOptions, registered map[int]float64) ProviderGrades {
+	scale := opts.scale(rating.ProviderID, rating.ProviderName, registered)
+	provider := ProviderGrades{
+		ProviderID:   rating.ProviderID,
+		ProviderName: rating.ProviderName,
+		Scale:        scale,
+		ReviewCount:  rating.ReviewCount,
+		Grades:       make(map[string]*float64, len(GradeCategories)),
+	}
+	for category, grade := range gradesOf(rating) {
+		provider.Grades[category] = normaliseGrade(grade, scale)
+	}
+	return provider
+}
+
+// CompareProviderGrades compares a hotel's category grades across providers
+// on a common 0-100 scale and flags categories where the spread exceeds the
+// threshold
+func (a AnalyticsModel) CompareProviderGrades(ctx context.Context, hotel *Hotel, opts GradeComparisonOptions) (*GradeComparison, error) {
+	ratings, err := HotelProviderRatingModel{DB: a.DB}.GetForHotel(ctx, hotel.HotelID)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := ProviderModel{DB: a.DB}.Scales(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &GradeComparison{
+		HotelID:    hotel.HotelID,
+		Platform:   hotel.Platform,
+		Threshold:  opts.Threshold,
+		Providers:  []ProviderGrades{},
+		Categories: []CategoryComparison{},
+	}
+
+	for _, rating := range ratings {
+		comparison.Providers = append(comparison.Providers, providerGrades(rating, opts, registered))
+	}
+
+	var platformAverages map[string]*float64
+	if opts.PlatformAverage {
+		platformAverages, err = a.platformGradeAverages(ctx, hotel.Platform, opts, registered)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, category := range GradeCategories {
+		c := CategoryComparison{
+			Category: category,
+			Scores:   make(map[string]*float64, len(comparison.Providers)),
+		}
+
+		for _, provider := range comparison.Providers {
+			score := provider.Grades[category]
+			c.Scores[provider.ProviderName] = score
+			if score == nil {
+				continue
+			}
+			if c.Min == nil || *score < *c.Min {
+				c.Min = score
+			}
+			if c.Max == nil || *score > *c.Max {
+				c.Max = score
+			}
+		}
+
+		if c.Min != nil && c.Max != nil {
+			spread := math.Round((*c.Max-*c.Min)*10) / 10
+			c.Spread = &spread
+			c.Disagreement = spread > opts.Threshold
+		}
+
+		if avg, ok := platformAverages[category]; ok && avg != nil {
+			c.PlatformAverage = avg
+			if mean := meanScore(c.Scores); mean != nil {
+				delta := math.Round((*mean-*avg)*10) / 10
+				c.DeltaFromPlatform = &delta
+			}
+		}
+
+		comparison.Categories = append(comparison.Categories, c)
+	}
+
+	return comparison, nil
+}
+
+// platformGradeAverages returns the per-category average of every hotel on
+// the platform, normalised per provider and weighted by the number of
+// hotels each provider rated. Ratings are grouped by provider id, so the
+// rows of a provider a feed renamed stay one group.
+func (a AnalyticsModel) platformGradeAverages(ctx context.Context, platform string, opts GradeComparisonOptions, registered map[int]float64) (map[string]*float64, error) {
+	query := `SELECT hpr.provider_id, p.name,
+		count(nullif(hpr.overall_score, 0)), avg(nullif(hpr.overall_score, 0))::float8,
+		count(nullif(hpr.cleanliness, 0)), avg(nullif(hpr.cleanliness, 0))::float8,
+		count(nullif(hpr.facilities, 0)), avg(nullif(hpr.facilities, 0))::float8,
+		count(nullif(hpr.location, 0)), avg(nullif(hpr.location, 0))::float8,
+		count(nullif(hpr.room_comfort_quality, 0)), avg(nullif(hpr.room_comfort_quality, 0))::float8,
+		count(nullif(hpr.service, 0)), avg(nullif(hpr.service, 0))::float8,
+		count(nullif(hpr.value_for_money, 0)), avg(nullif(hpr.value_for_money, 0))::float8
+	FROM hotel_provider_ratings hpr
+	JOIN hotels h ON h.hotel_id = hpr.hotel_id
+	JOIN providers p ON p.id = hpr.provider_id
+	WHERE h.platform = $1
+	GROUP BY hpr.provider_id, p.name`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, platform)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var providerID int
+		var providerName string
+		n := make([]int, len(GradeCategories))
+		avg := make([]*float64, len(GradeCategories))
+
+		dest := []interface{}{&providerID, &providerName}
+		for i := range GradeCategories {
+			dest = append(dest, &n[i], &avg[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		scale := opts.scale(providerID, providerName, registered)
+		for i, category := range GradeCategories {
+			if normalised := normaliseGrade(avg[i], scale); normalised != nil {
+				sums[category] += *normalised * float64(n[i])
+				counts[category] += n[i]
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	averages := make(map[string]*float64, len(GradeCategories))
+	for _, category := range GradeCategories {
+		if counts[category] > 0 {
+			avg := math.Round(sums[category]/float64(counts[category])*10) / 10
+			averages[category] = &avg
+		}
+	}
+
+	return averages, nil
+}
+
+// meanScore averages the non-nil scores
+func meanScore(scores map[string]*float64) *float64 {
+	var sum float64
+	var n int
+	for _, score := range scores {
+		if score != nil {
+			sum += *score
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+
+	mean := sum / float64(n)
+	return &mean
+}
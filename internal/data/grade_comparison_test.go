@@ -0,0 +1,91 @@
+package data
+
+import "testing"
+
+func TestNormaliseGrade(t *testing.T) {
+	grade := func(f float64) *float64 { return &f }
+
+	tests := []struct {
+		name  string
+		grade *float64
+		scale float64
+		want  *float64
+	}{
+		{"ten point scale", grade(8.4), 10, grade(84)},
+		{"five point scale", grade(4.5), 5, grade(90)},
+		{"hundred point scale", grade(73), 100, grade(73)},
+		{"rounded to one decimal", grade(2), 3, grade(66.7)},
+		{"top of scale", grade(10), 10, grade(100)},
+		{"missing", nil, 10, nil},
+		{"zero is missing", grade(0), 10, nil},
+		{"negative", grade(-1), 10, nil},
+		{"no scale", grade(8), 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normaliseGrade(tt.grade, tt.scale)
+			switch {
+			case got == nil && tt.want == nil:
+			case got == nil || tt.want == nil || *got != *tt.want:
+				t.Errorf("normaliseGrade() = %v, want %v", deref(got), deref(tt.want))
+			}
+		})
+	}
+}
+
+func TestGradeComparisonScale(t *testing.T) {
+	registered := map[int]float64{1: 10, 2: 5, 3: 0}
+	opts := GradeComparisonOptions{Scales: map[string]float64{"expedia": 100, "agoda": 0}}
+
+	tests := []struct {
+		name         string
+		providerID   int
+		providerName string
+		want         float64
+	}{
+		{"registered", 1, "booking", 10},
+		{"override", 2, "expedia", 100},
+		{"zero override", 4, "agoda", DefaultRatingScale},
+		{"zero registered", 3, "broken", DefaultRatingScale},
+		{"unknown", 5, "unknown", DefaultRatingScale},
+		{"renamed provider", 2, "Expedia Group", 5},
+	}
+
+	for _, tt := range tests {
+		if got := opts.scale(tt.providerID, tt.providerName, registered); got != tt.want {
+			t.Errorf("%s: scale(%d, %q) = %v, want %v", tt.name, tt.providerID, tt.providerName, got, tt.want)
+		}
+	}
+}
+
+func TestProviderGradesRenamedProvider(t *testing.T) {
+	registered := map[int]float64{7: 5}
+	cleanliness := 4.5
+
+	// The feed renamed "Booking.com" to "Booking"; both rows are provider 7
+	for _, name := range []string{"Booking.com", "Booking"} {
+		rating := &HotelProviderRating{ProviderID: 7, ProviderName: name, OverallScore: 4, Cleanliness: &cleanliness}
+		got := providerGrades(rating, GradeComparisonOptions{}, registered)
+
+		if got.Scale != 5 {
+			t.Errorf("%s: Scale = %v, want 5", name, got.Scale)
+		}
+		if g := got.Grades["overall"]; g == nil || *g != 80 {
+			t.Errorf("%s: overall = %v, want 80", name, deref(g))
+		}
+		if g := got.Grades["cleanliness"]; g == nil || *g != 90 {
+			t.Errorf("%s: cleanliness = %v, want 90", name, deref(g))
+		}
+		if g := got.Grades["location"]; g != nil {
+			t.Errorf("%s: location = %v, want missing", name, *g)
+		}
+	}
+}
+
+func deref(f *float64) any {
+	if f == nil {
+		return nil
+	}
+	return *f
+}
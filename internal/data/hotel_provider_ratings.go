@@ -6,20 +6,20 @@ import (
 )
 
 type HotelProviderRating struct {
-	ID                 int64
-	HotelID            int64
-	ProviderID         int
-	ProviderName       string
-	OverallScore       float64
-	ReviewCount        int
-	Cleanliness        *float64
-	Facilities         *float64
-	Location           *float64
-	RoomComfortQuality *float64
-	Service            *float64
-	ValueForMoney      *float64
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	ID                 int64     `json:"-"`
+	HotelID            int64     `json:"hotel_id"`
+	ProviderID         int       `json:"provider_id"`
+	ProviderName       string    `json:"provider_name"`
+	OverallScore       float64   `json:"overall_score"`
+	ReviewCount        int       `json:"review_count"`
+	Cleanliness        *float64  `json:"cleanliness"`
+	Facilities         *float64  `json:"facilities"`
+	Location           *float64  `json:"location"`
+	RoomComfortQuality *float64  `json:"room_comfort_quality"`
+	Service            *float64  `json:"service"`
+	ValueForMoney      *float64  `json:"value_for_money"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
 }
 
 type HotelProviderRatingModel struct {
@@ -32,6 +32,11 @@ func (h HotelProviderRatingModel) Create(rating *HotelProviderRating) error {
 		cleanliness, facilities, location, room_comfort_quality, service, value_for_money
 	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	ON CONFLICT (hotel_id, provider_id) DO UPDATE SET
+		previous_overall_score = CASE WHEN hotel_provider_ratings.overall_score <> EXCLUDED.overall_score
+			THEN hotel_provider_ratings.overall_score ELSE hotel_provider_ratings.previous_overall_score END,
+		overall_score_changed_at = CASE WHEN hotel_provider_ratings.overall_score <> EXCLUDED.overall_score
+			THEN CURRENT_TIMESTAMP ELSE hotel_provider_ratings.overall_score_changed_at END,
+		provider_name = EXCLUDED.provider_name,
 		overall_score = EXCLUDED.overall_score,
 		review_count = EXCLUDED.review_count,
 		cleanliness = EXCLUDED.cleanliness,
@@ -62,3 +67,57 @@ func (h HotelProviderRatingModel) Create(rating *HotelProviderRating) error {
 
 	return h.DB.QueryRowContext(ctx, query, args...).Scan(&rating.ID, &rating.CreatedAt, &rating.UpdatedAt)
 }
+
+// GetForHotel returns the per-provider ratings of a hotel ordered by provider
+// name. The name is the provider's, not the one the feed last sent, which a
+// renamed provider changes.
+func (h HotelProviderRatingModel) GetForHotel(ctx context.Context, hotelID int64) ([]*HotelProviderRating, error) {
+	query := `SELECT hpr.id, hpr.hotel_id, hpr.provider_id, p.name, hpr.overall_score, hpr.review_count,
+		hpr.cleanliness, hpr.facilities, hpr.location, hpr.room_comfort_quality, hpr.service, hpr.value_for_money,
+		hpr.created_at, hpr.updated_at
+	FROM hotel_provider_ratings hpr
+	JOIN providers p ON p.id = hpr.provider_id
+	WHERE hpr.hotel_id = $1
+	ORDER BY p.name`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.QueryContext(ctx, query, hotelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := []*HotelProviderRating{}
+
+	for rows.Next() {
+		var rating HotelProviderRating
+		err := rows.Scan(
+			&rating.ID,
+			&rating.HotelID,
+			&rating.ProviderID,
+			&rating.ProviderName,
+			&rating.OverallScore,
+			&rating.ReviewCount,
+			&rating.Cleanliness,
+			&rating.Facilities,
+			&rating.Location,
+			&rating.RoomComfortQuality,
+			&rating.Service,
+			&rating.ValueForMoney,
+			&rating.CreatedAt,
+			&rating.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, &rating)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ratings, nil
+}
@@ -2,16 +2,20 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 type Hotel struct {
-	ID        int64
-	HotelID   int64
-	Name      string
-	Platform  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID        int64     `json:"-"`
+	HotelID   int64     `json:"hotel_id"`
+	Name      string    `json:"name"`
+	Platform  string    `json:"platform"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type HotelModel struct {
@@ -40,9 +44,14 @@ func (h HotelModel) Create(hotel *Hotel) error {
 }
 
 func (h HotelModel) GetByHotelID(hotelID int64) (*Hotel, error) {
+	return h.Get(context.Background(), hotelID)
+}
+
+// Get returns the hotel with the given upstream hotel ID, or ErrRecordNotFound
+func (h HotelModel) Get(ctx context.Context, hotelID int64) (*Hotel, error) {
 	query := `SELECT id, hotel_id, name, platform, created_at, updated_at FROM hotels WHERE hotel_id = $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
 	hotel := &Hotel{}
@@ -55,7 +64,132 @@ func (h HotelModel) GetByHotelID(hotelID int64) (*Hotel, error) {
 		&hotel.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
 	}
 	return hotel, nil
 }
+
+// GetVersion returns when the data of a hotel last changed: the latest
+// updated_at of the hotel, its reviews, its provider ratings and its daily
+// rollup rows. Unknown hotels return ErrRecordNotFound.
+func (h HotelModel) GetVersion(ctx context.Context, hotelID int64) (time.Time, error) {
+	query := `SELECT greatest(
+		coalesce(h.updated_at, h.created_at, 'epoch'::timestamp),
+		(SELECT max(r.updated_at) FROM reviews r WHERE r.hotel_id = h.hotel_id),
+		(SELECT max(hpr.updated_at) FROM hotel_provider_ratings hpr WHERE hpr.hotel_id = h.hotel_id),
+		(SELECT max(d.updated_at)::timestamp FROM hotel_daily_stats d WHERE d.hotel_id = h.hotel_id))
+	FROM hotels h
+	WHERE h.hotel_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var version time.Time
+	err := h.DB.QueryRowContext(ctx, query, hotelID).Scan(&version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return time.Time{}, ErrRecordNotFound
+		default:
+			return time.Time{}, err
+		}
+	}
+
+	return version, nil
+}
+
+// HotelFilter narrows GetAll. Empty fields are not applied.
+type HotelFilter struct {
+	Platform   string
+	NamePrefix string
+	Filters
+}
+
+// GetAll returns one page of hotels matching the filter along with the
+// pagination metadata
+func (h HotelModel) GetAll(ctx context.Context, filter HotelFilter) ([]*Hotel, Metadata, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, hotel_id, name, platform, created_at, updated_at
+	FROM hotels
+	WHERE (platform = $1 OR $1 = '')
+	AND (lower(name) LIKE $2 || '%%' ESCAPE '\' OR $2 = '')
+	ORDER BY %s %s, hotel_id ASC
+	LIMIT $3 OFFSET $4`, filter.sortColumn(), filter.sortDirection())
+
+	args := []interface{}{
+		filter.Platform,
+		escapeLike(strings.ToLower(filter.NamePrefix)),
+		filter.limit(),
+		filter.offset(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	hotels := []*Hotel{}
+
+	for rows.Next() {
+		var hotel Hotel
+		err := rows.Scan(
+			&totalRecords,
+			&hotel.ID,
+			&hotel.HotelID,
+			&hotel.Name,
+			&hotel.Platform,
+			&hotel.CreatedAt,
+			&hotel.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		hotels = append(hotels, &hotel)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filter.Page, filter.PageSize)
+
+	return hotels, metadata, nil
+}
+
+// GetIDs returns the hotel_id of every hotel in order
+func (h HotelModel) GetIDs(ctx context.Context) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.QueryContext(ctx, `SELECT hotel_id FROM hotels ORDER BY hotel_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hotelIDs := []int64{}
+	for rows.Next() {
+		var hotelID int64
+		if err := rows.Scan(&hotelID); err != nil {
+			return nil, err
+		}
+		hotelIDs = append(hotelIDs, hotelID)
+	}
+
+	return hotelIDs, rows.Err()
+}
+
+// escapeLike escapes the LIKE wildcards in user input so it matches literally
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(s)
+}
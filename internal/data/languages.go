@@ -0,0 +1,89 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ReviewLanguage is the detected language of one review, with empty
+// Language for texts langid couldn't identify
+type ReviewLanguage struct {
+	ID         int64
+	Language   string
+	Confidence float64
+}
+
+// ListUndetected returns up to limit reviews after afterID, in id order,
+// whose language was not detected by version. With all set every review is
+// returned.
+func (r ReviewModel) ListUndetected(ctx context.Context, version int, all bool, afterID int64, limit int) ([]*ReviewText, error) {
+	query := `SELECT id, coalesce(review_positives, ''), coalesce(review_negatives, ''), coalesce(review_comments, ''),
+		coalesce(original_comment, '')
+	FROM reviews
+	WHERE id > $1
+	AND ($2::boolean OR language_version IS DISTINCT FROM $3)
+	ORDER BY id
+	LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, afterID, all, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := []*ReviewText{}
+	for rows.Next() {
+		var text ReviewText
+		if err := rows.Scan(&text.ID, &text.Positives, &text.Negatives, &text.Comments, &text.OriginalComment); err != nil {
+			return nil, err
+		}
+		texts = append(texts, &text)
+	}
+
+	return texts, rows.Err()
+}
+
+// SetLanguages stores the languages detected by version and returns the
+// number of reviews updated. updated_at is bumped on reviews whose language
+// changed, so cached listings and stats are revalidated.
+func (r ReviewModel) SetLanguages(ctx context.Context, version int, languages []ReviewLanguage) (int64, error) {
+	if len(languages) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(languages))
+	codes := make([]sql.NullString, len(languages))
+	confidences := make([]sql.NullFloat64, len(languages))
+	for i, language := range languages {
+		ids[i] = language.ID
+		if language.Language != "" {
+			codes[i] = sql.NullString{String: language.Language, Valid: true}
+			confidences[i] = sql.NullFloat64{Float64: language.Confidence, Valid: true}
+		}
+	}
+
+	query := `UPDATE reviews r
+	SET language = d.language, language_confidence = d.confidence, language_version = $4,
+		updated_at = CASE
+			WHEN r.language IS DISTINCT FROM d.language OR r.language_confidence IS DISTINCT FROM d.confidence
+			THEN CURRENT_TIMESTAMP ELSE r.updated_at
+		END
+	FROM unnest($1::bigint[], $2::text[], $3::numeric[]) AS d(id, language, confidence)
+	WHERE r.id = d.id`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, pq.Array(ids), pq.GenericArray{A: codes}, pq.GenericArray{A: confidences}, version)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
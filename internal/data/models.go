@@ -19,22 +19,42 @@ type DBTX interface {
 
 type Models struct {
 	ProcessedFiles      ProcessedFileModel
+	ProcessingErrors    ProcessingErrorModel
 	Hotel               HotelModel
 	Review              ReviewModel
 	HotelProviderRating HotelProviderRatingModel
 	Provider            ProviderModel
 	Country             CountryModel
 	ReviewGroup         ReviewGroupModel
+	RoomTypes           RoomTypeModel
+	Lookups             LookupModel
+	ReviewAspects       ReviewAspectModel
+	Alerts              AlertModel
+	Duplicates          DuplicateModel
+	Analytics           AnalyticsModel
+	DailyStats          DailyStatsModel
+	APIKeys             APIKeyModel
+	RateLimits          RateLimitModel
 }
 
 func NewModels(dbtx DBTX) Models {
 	return Models{
 		ProcessedFiles:      ProcessedFileModel{DB: dbtx},
+		ProcessingErrors:    ProcessingErrorModel{DB: dbtx},
 		Hotel:               HotelModel{DB: dbtx},
 		Review:              ReviewModel{DB: dbtx},
 		HotelProviderRating: HotelProviderRatingModel{DB: dbtx},
 		Provider:            ProviderModel{DB: dbtx},
 		Country:             CountryModel{DB: dbtx},
 		ReviewGroup:         ReviewGroupModel{DB: dbtx},
+		RoomTypes:           RoomTypeModel{DB: dbtx},
+		Lookups:             LookupModel{DB: dbtx},
+		ReviewAspects:       ReviewAspectModel{DB: dbtx},
+		Alerts:              AlertModel{DB: dbtx},
+		Duplicates:          DuplicateModel{DB: dbtx},
+		Analytics:           AnalyticsModel{DB: dbtx},
+		DailyStats:          DailyStatsModel{DB: dbtx},
+		APIKeys:             APIKeyModel{DB: dbtx},
+		RateLimits:          RateLimitModel{DB: dbtx},
 	}
 }
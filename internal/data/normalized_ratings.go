@@ -0,0 +1,58 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// normalizedRating maps rating r to 0-100 on the registered scale of its
+// provider p, rounded to one decimal. Ratings above the scale count as 100.
+const normalizedRating = `least(round(r.rating / p.rating_scale * 100, 1), 100)`
+
+// NormalizeRatings recomputes normalized_rating from the registered provider
+// scales for up to limit reviews after afterID, in id order. It returns the
+// last id it looked at, zero when there are no more reviews, and the hotel
+// days of the reviews whose value changed, for refreshing the rollup.
+// updated_at is only bumped on changed reviews, so a rerun is cheap.
+func (r ReviewModel) NormalizeRatings(ctx context.Context, afterID int64, limit int) (int64, []DailyStatsKey, error) {
+	query := `WITH batch AS (
+		SELECT id FROM reviews WHERE id > $1 ORDER BY id LIMIT $2
+	),
+	changed AS (
+		UPDATE reviews r
+		SET normalized_rating = ` + normalizedRating + `, updated_at = CURRENT_TIMESTAMP
+		FROM providers p
+		WHERE p.id = r.provider_id
+		AND r.id IN (SELECT id FROM batch)
+		AND r.normalized_rating IS DISTINCT FROM ` + normalizedRating + `
+		RETURNING r.hotel_id, r.review_date
+	)
+	SELECT (SELECT coalesce(max(id), 0) FROM batch), c.hotel_id, c.review_date::date
+	FROM (SELECT 1) one
+	LEFT JOIN (SELECT DISTINCT hotel_id, review_date::date AS review_date FROM changed WHERE review_date IS NOT NULL) c ON true`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+
+	var lastID int64
+	keys := []DailyStatsKey{}
+
+	for rows.Next() {
+		var hotelID *int64
+		var day *time.Time
+		if err := rows.Scan(&lastID, &hotelID, &day); err != nil {
+			return 0, nil, err
+		}
+		if hotelID != nil && day != nil {
+			keys = append(keys, DailyStatsKey{HotelID: *hotelID, Day: *day})
+		}
+	}
+
+	return lastID, keys, rows.Err()
+}
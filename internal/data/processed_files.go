@@ -3,19 +3,35 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 )
 
+const (
+	FileStatusProcessing  = "Processing"
+	FileStatusSuccess     = "Success"
+	FileStatusPartial     = "Partial"
+	FileStatusFailed      = "Failed"
+	FileStatusInterrupted = "Interrupted"
+)
+
+// StaleProcessingAfter is how long a Processing record may go untouched
+// before its run is taken to have crashed. Runs touch their record after
+// every batch.
+const StaleProcessingAfter = 10 * time.Minute
+
 type ProcessedFile struct {
-	ID           int64
-	Filename     string
-	S3Path       string
-	ProcessedAt  time.Time
-	RecordsCount int
-	ErrorsCount  int
-	Status       string // Success, Failed, Partial
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ID                int64     `json:"id"`
+	Filename          string    `json:"filename"`
+	S3Path            string    `json:"s3_path"`
+	ProcessedAt       time.Time `json:"processed_at"`
+	RecordsCount      int       `json:"records_count"`
+	ErrorsCount       int       `json:"errors_count"`
+	Status            string    `json:"status"`              // Processing, Success, Failed, Partial, Interrupted
+	LastCommittedLine int       `json:"last_committed_line"` // Last input line whose batch was fully handled, used to resume Interrupted files
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
 
 type ProcessedFileModel struct {
@@ -45,13 +61,14 @@ func (p ProcessedFileModel) Create(file *ProcessedFile) error {
 
 func (p ProcessedFileModel) Update(file *ProcessedFile) error {
 	query := `UPDATE processed_files 
-	SET records_count = $1, errors_count = $2, status = $3, updated_at = CURRENT_TIMESTAMP
-	WHERE id = $4`
+	SET records_count = $1, errors_count = $2, status = $3, last_committed_line = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $5`
 
 	args := []interface{}{
 		file.RecordsCount,
 		file.ErrorsCount,
 		file.Status,
+		file.LastCommittedLine,
 		file.ID,
 	}
 
@@ -62,6 +79,46 @@ func (p ProcessedFileModel) Update(file *ProcessedFile) error {
 	return err
 }
 
+// Touch bumps updated_at of a record being processed, so the run isn't
+// taken for one that crashed
+func (p ProcessedFileModel) Touch(id int64) error {
+	query := `UPDATE processed_files SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := p.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkAbandoned marks a Processing record untouched for StaleProcessingAfter
+// as Interrupted, so the next run of the file resumes it from its last
+// committed line. It returns ErrEditConflict if the record isn't
+// Processing or its run touched it more recently.
+func (p ProcessedFileModel) MarkAbandoned(ctx context.Context, id int64) error {
+	query := `UPDATE processed_files
+	SET status = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND status = $3 AND updated_at < CURRENT_TIMESTAMP - make_interval(secs => $4)`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, query, id, FileStatusInterrupted, FileStatusProcessing, StaleProcessingAfter.Seconds())
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrEditConflict
+	}
+
+	return nil
+}
+
 func (p ProcessedFileModel) IsProcessed(s3Path string) (bool, error) {
 	query := `SELECT id FROM processed_files WHERE s3path = $1 AND status IN ('Success', 'Partial')`
 
@@ -78,3 +135,133 @@ func (p ProcessedFileModel) IsProcessed(s3Path string) (bool, error) {
 	}
 	return true, nil
 }
+
+// GetInterrupted returns the most recent Interrupted record for the path, or
+// ErrRecordNotFound if the file has no pending resume point.
+func (p ProcessedFileModel) GetInterrupted(s3Path string) (*ProcessedFile, error) {
+	query := `SELECT id, filename, s3path, processed_at, records_count, errors_count, status, last_committed_line, created_at, updated_at
+	FROM processed_files
+	WHERE s3path = $1 AND status = $2
+	ORDER BY updated_at DESC
+	LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	file := &ProcessedFile{}
+	err := p.DB.QueryRowContext(ctx, query, s3Path, FileStatusInterrupted).Scan(
+		&file.ID,
+		&file.Filename,
+		&file.S3Path,
+		&file.ProcessedAt,
+		&file.RecordsCount,
+		&file.ErrorsCount,
+		&file.Status,
+		&file.LastCommittedLine,
+		&file.CreatedAt,
+		&file.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// Get returns the processed file record with the given id, or ErrRecordNotFound
+func (p ProcessedFileModel) Get(ctx context.Context, id int64) (*ProcessedFile, error) {
+	query := `SELECT id, filename, s3path, processed_at, records_count, errors_count, status, last_committed_line, created_at, updated_at
+	FROM processed_files
+	WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	file := &ProcessedFile{}
+	err := p.DB.QueryRowContext(ctx, query, id).Scan(
+		&file.ID,
+		&file.Filename,
+		&file.S3Path,
+		&file.ProcessedAt,
+		&file.RecordsCount,
+		&file.ErrorsCount,
+		&file.Status,
+		&file.LastCommittedLine,
+		&file.CreatedAt,
+		&file.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// ProcessedFileFilter narrows GetAll. Empty and nil fields are not applied.
+type ProcessedFileFilter struct {
+	Status string
+	From   *time.Time // processed_at >= From
+	To     *time.Time // processed_at < To
+	Filters
+}
+
+// GetAll returns one page of processed file records matching the filter
+func (p ProcessedFileModel) GetAll(ctx context.Context, filter ProcessedFileFilter) ([]*ProcessedFile, Metadata, error) {
+	query := fmt.Sprintf(`SELECT count(*) OVER(), id, filename, s3path, processed_at, records_count, errors_count,
+		status, last_committed_line, created_at, updated_at
+	FROM processed_files
+	WHERE ($1::text = '' OR status = $1)
+	AND ($2::timestamptz IS NULL OR processed_at >= $2)
+	AND ($3::timestamptz IS NULL OR processed_at < $3)
+	ORDER BY %s %s, id DESC
+	LIMIT $4 OFFSET $5`, filter.sortColumn(), filter.sortDirection())
+
+	args := []interface{}{filter.Status, filter.From, filter.To, filter.limit(), filter.offset()}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	files := []*ProcessedFile{}
+
+	for rows.Next() {
+		var file ProcessedFile
+		err := rows.Scan(
+			&totalRecords,
+			&file.ID,
+			&file.Filename,
+			&file.S3Path,
+			&file.ProcessedAt,
+			&file.RecordsCount,
+			&file.ErrorsCount,
+			&file.Status,
+			&file.LastCommittedLine,
+			&file.CreatedAt,
+			&file.UpdatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		files = append(files, &file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filter.Page, filter.PageSize)
+
+	return files, metadata, nil
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lib/pq"
+)
+
+// ProcessingError is a record-level import error persisted for a processed file
+type ProcessingError struct {
+	ID              int64     `json:"id"`
+	ProcessedFileID int64     `json:"processed_file_id"`
+	LineNumber      int       `json:"line_number"`
+	Category        string    `json:"category"`
+	Message         string    `json:"message"`
+	RawData         string    `json:"raw_data,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// MaxRawData caps the raw line stored with an error, in bytes
+const MaxRawData = 4096
+
+type ProcessingErrorModel struct {
+	DB DBTX
+}
+
+// storableText makes s safe for a text column: invalid UTF-8 and NUL bytes,
+// which Postgres rejects, are dropped, and the rest is cut to at most max
+// bytes on a rune boundary. A max of 0 keeps the whole text.
+func storableText(s string, max int) string {
+	s = strings.ReplaceAll(strings.ToValidUTF8(s, ""), "\x00", "")
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// InsertMany stores the errors of one processed file in a single statement.
+// Messages and raw lines are made storable first, and raw lines longer than
+// MaxRawData are truncated.
+func (p ProcessingErrorModel) InsertMany(fileID int64, errs []*ProcessingError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	lineNumbers := make([]int64, 0, len(errs))
+	categories := make([]string, 0, len(errs))
+	messages := make([]string, 0, len(errs))
+	rawData := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		lineNumbers = append(lineNumbers, int64(e.LineNumber))
+		categories = append(categories, e.Category)
+		messages = append(messages, storableText(e.Message, 0))
+		rawData = append(rawData, storableText(e.RawData, MaxRawData))
+	}
+
+	query := `INSERT INTO processing_errors (processed_file_id, line_number, category, message, raw_data)
+	SELECT $1, unnest($2::int[]), unnest($3::text[]), unnest($4::text[]), unnest($5::text[])`
+
+	args := []interface{}{
+		fileID,
+		pq.Array(lineNumbers),
+		pq.Array(categories),
+		pq.Array(messages),
+		pq.Array(rawData),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := p.DB.ExecContext(ctx, query, args...)
+	return err
+}
+
+// ProcessingErrorFilter narrows GetAllForFile. An empty Category is not applied.
+type ProcessingErrorFilter struct {
+	Category string
+	Filters
+}
+
+// GetAllForFile returns one page of a processed file's errors in line order
+func (p ProcessingErrorModel) GetAllForFile(ctx context.Context, fileID int64, filter ProcessingErrorFilter) ([]*ProcessingError, Metadata, error) {
+	query := `SELECT count(*) OVER(), id, processed_file_id, line_number, category, message, raw_data, created_at
+	FROM processing_errors
+	WHERE processed_file_id = $1
+	AND ($2::text = '' OR category = $2)
+	ORDER BY line_number, id
+	LIMIT $3 OFFSET $4`
+
+	args := []interface{}{fileID, filter.Category, filter.limit(), filter.offset()}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	errs := []*ProcessingError{}
+
+	for rows.Next() {
+		var e ProcessingError
+		err := rows.Scan(
+			&totalRecords,
+			&e.ID,
+			&e.ProcessedFileID,
+			&e.LineNumber,
+			&e.Category,
+			&e.Message,
+			&e.RawData,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		errs = append(errs, &e)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, filter.Page, filter.PageSize)
+
+	return errs, metadata, nil
+}
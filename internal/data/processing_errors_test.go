@@ -0,0 +1,37 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestStorableText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		max  int
+		want string
+	}{
+		{"plain", `{"hotelId": 1}`, 100, `{"hotelId": 1}`},
+		{"no limit", strings.Repeat("a", 5000), 0, strings.Repeat("a", 5000)},
+		{"nul bytes", "{\"a\":\x00\"b\x00\"}", 100, `{"a":"b"}`},
+		{"invalid utf-8", "caf\xe9 ok", 100, "caf ok"},
+		{"truncated", "abcdef", 4, "abcd"},
+		{"truncated before a split rune", "abcé", 4, "abc"},
+		{"truncated after a whole rune", "abé", 4, "abé"},
+		{"nul bytes don't count towards max", "\x00\x00\x00abc", 3, "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := storableText(tt.text, tt.max)
+			if got != tt.want {
+				t.Errorf("storableText(%q, %d) = %q, want %q", tt.text, tt.max, got, tt.want)
+			}
+			if !utf8.ValidString(got) || strings.ContainsRune(got, 0) {
+				t.Errorf("storableText(%q, %d) = %q is not storable", tt.text, tt.max, got)
+			}
+		})
+	}
+}
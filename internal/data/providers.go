@@ -3,13 +3,26 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
 )
 
+// DefaultRatingScale is the rating scale new providers are registered with
+const DefaultRatingScale = 10.0
+
 type Provider struct {
-	ID        int
-	Name      string
-	CreatedAt time.Time
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	RatingScale float64   `json:"rating_scale"` // Maximum rating on the provider's scale
+	ReviewCount int       `json:"review_count,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ValidateRatingScale(v *validator.Validator, scale float64) {
+	v.Check(scale > 0, "scale", "must be greater than zero")
+	v.Check(scale <= 1000, "scale", "must not be more than 1000")
 }
 
 type ProviderModel struct {
@@ -18,13 +31,13 @@ type ProviderModel struct {
 
 func (p ProviderModel) CreateOrGet(name string) (*Provider, error) {
 	// First try to get existing provider
-	query := `SELECT id, name, created_at FROM providers WHERE name = $1`
+	query := `SELECT id, name, rating_scale, created_at FROM providers WHERE name = $1`
 	provider := &Provider{}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := p.DB.QueryRowContext(ctx, query, name).Scan(&provider.ID, &provider.Name, &provider.CreatedAt)
+	err := p.DB.QueryRowContext(ctx, query, name).Scan(&provider.ID, &provider.Name, &provider.RatingScale, &provider.CreatedAt)
 	if err == nil {
 		return provider, nil
 	}
@@ -33,7 +46,129 @@ func (p ProviderModel) CreateOrGet(name string) (*Provider, error) {
 	}
 
 	// Create new provider if not found
-	insertQuery := `INSERT INTO providers (name) VALUES ($1) RETURNING id, name, created_at`
-	err = p.DB.QueryRowContext(ctx, insertQuery, name).Scan(&provider.ID, &provider.Name, &provider.CreatedAt)
+	insertQuery := `INSERT INTO providers (name) VALUES ($1) RETURNING id, name, rating_scale, created_at`
+	err = p.DB.QueryRowContext(ctx, insertQuery, name).Scan(&provider.ID, &provider.Name, &provider.RatingScale, &provider.CreatedAt)
+	return provider, err
+}
+
+// Resolve returns the provider a platform sends as externalID and name. The
+// external id is matched first, following a renamed provider; without a
+// mapping the provider is looked up by name, including the names platforms
+// renamed it to, and the id mapped onto it. An externalID of 0 means the
+// feed sent none.
+func (p ProviderModel) Resolve(platform string, externalID int, name string) (*Provider, error) {
+	if externalID != 0 {
+		id, err := providerExternalIDs.resolve(p.DB, platform, externalID, name)
+		switch {
+		case err == nil:
+			return p.get(id)
+		case !errors.Is(err, ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	var provider *Provider
+	id, err := providerExternalIDs.byName(p.DB, name)
+	switch {
+	case err == nil:
+		provider, err = p.get(id)
+	case errors.Is(err, ErrRecordNotFound):
+		provider, err = p.CreateOrGet(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if externalID == 0 {
+		return provider, nil
+	}
+
+	return provider, providerExternalIDs.add(p.DB, platform, externalID, provider.ID, name)
+}
+
+func (p ProviderModel) get(id int) (*Provider, error) {
+	query := `SELECT id, name, rating_scale, created_at FROM providers WHERE id = $1`
+	provider := &Provider{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := p.DB.QueryRowContext(ctx, query, id).Scan(&provider.ID, &provider.Name, &provider.RatingScale, &provider.CreatedAt)
 	return provider, err
 }
+
+// GetAll returns every provider with its number of reviews, by name
+func (p ProviderModel) GetAll(ctx context.Context) ([]*Provider, error) {
+	query := `SELECT p.id, p.name, p.rating_scale, p.created_at,
+		(SELECT count(*) FROM reviews r WHERE r.provider_id = p.id)
+	FROM providers p
+	ORDER BY p.name`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	providers := []*Provider{}
+	for rows.Next() {
+		var provider Provider
+		err := rows.Scan(&provider.ID, &provider.Name, &provider.RatingScale, &provider.CreatedAt, &provider.ReviewCount)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, &provider)
+	}
+
+	return providers, rows.Err()
+}
+
+// Scales returns the registered rating scale of every provider, by id
+func (p ProviderModel) Scales(ctx context.Context) (map[int]float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `SELECT id, rating_scale FROM providers`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scales := make(map[int]float64)
+	for rows.Next() {
+		var id int
+		var scale float64
+		if err := rows.Scan(&id, &scale); err != nil {
+			return nil, err
+		}
+		scales[id] = scale
+	}
+
+	return scales, rows.Err()
+}
+
+// SetRatingScale registers the rating scale of a provider by name. Reviews
+// keep their normalized rating until `ratings backfill` recomputes it.
+func (p ProviderModel) SetRatingScale(ctx context.Context, name string, scale float64) (*Provider, error) {
+	query := `UPDATE providers SET rating_scale = $2
+	WHERE name = $1
+	RETURNING id, name, rating_scale, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var provider Provider
+	err := p.DB.QueryRowContext(ctx, query, name, scale).Scan(&provider.ID, &provider.Name, &provider.RatingScale, &provider.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &provider, nil
+}
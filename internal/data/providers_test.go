@@ -0,0 +1,30 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+func TestValidateRatingScale(t *testing.T) {
+	tests := []struct {
+		scale float64
+		valid bool
+	}{
+		{DefaultRatingScale, true},
+		{5, true},
+		{0.5, true},
+		{1000, true},
+		{0, false},
+		{-10, false},
+		{1000.5, false},
+	}
+
+	for _, tt := range tests {
+		v := validator.New()
+		ValidateRatingScale(v, tt.scale)
+		if v.Valid() != tt.valid {
+			t.Errorf("ValidateRatingScale(%v) valid = %v, want %v (%v)", tt.scale, v.Valid(), tt.valid, v.Errors)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// RateLimitModel keeps token buckets and daily request counts in Postgres,
+// so several API replicas enforce one shared limit per key
+type RateLimitModel struct {
+	DB DBTX
+}
+
+// TakeToken refills the bucket of key id at rps up to burst and takes one
+// token if there is one. The upsert locks the row, so concurrent requests
+// from different replicas can't both take the last token. It returns
+// whether the request is allowed and the tokens left afterwards.
+func (m RateLimitModel) TakeToken(ctx context.Context, id int64, rps float64, burst int) (bool, float64, error) {
+	refilled := `least($3::float8, b.tokens + greatest(0, extract(epoch FROM now() - b.refilled_at)) * $2::float8)`
+
+	query := `INSERT INTO api_key_buckets AS b (api_key_id, tokens, allowed, refilled_at)
+	VALUES ($1, $3::float8 - 1, true, now())
+	ON CONFLICT (api_key_id) DO UPDATE SET
+		tokens = ` + refilled + ` - CASE WHEN ` + refilled + ` >= 1 THEN 1 ELSE 0 END,
+		allowed = ` + refilled + ` >= 1,
+		refilled_at = greatest(b.refilled_at, now())
+	RETURNING allowed, tokens`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var allowed bool
+	var tokens float64
+	err := m.DB.QueryRowContext(ctx, query, id, rps, burst).Scan(&allowed, &tokens)
+	return allowed, tokens, err
+}
+
+// ReturnToken puts back a token taken by TakeToken for a request that was
+// rejected for another reason, e.g. the daily quota
+func (m RateLimitModel) ReturnToken(ctx context.Context, id int64, burst int) error {
+	query := `UPDATE api_key_buckets SET tokens = least($2::float8, tokens + 1) WHERE api_key_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id, burst)
+	return err
+}
+
+// CountRequest adds one request to the usage of key id on day (UTC) unless
+// the day's total has reached quota. It returns the day's total and whether
+// the request was counted.
+func (m RateLimitModel) CountRequest(ctx context.Context, id int64, day time.Time, quota int) (int, bool, error) {
+	query := `INSERT INTO api_key_usage AS u (api_key_id, day, requests)
+	VALUES ($1, $2, 1)
+	ON CONFLICT (api_key_id, day) DO UPDATE SET requests = u.requests + 1
+	WHERE u.requests < $3
+	RETURNING requests`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var requests int
+	err := m.DB.QueryRowContext(ctx, query, id, day.UTC().Format(time.DateOnly), quota).Scan(&requests)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// The WHERE skipped the update, so the quota is used up
+			return quota, false, nil
+		default:
+			return 0, false, err
+		}
+	}
+
+	return requests, true, nil
+}
+
+// DeleteUsageBefore removes daily counts older than day
+func (m RateLimitModel) DeleteUsageBefore(ctx context.Context, day time.Time) error {
+	query := `DELETE FROM api_key_usage WHERE day < $1`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, day.UTC().Format(time.DateOnly))
+	return err
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mahesh-singh/review-system/internal/aspects"
+)
+
+// ReviewAspectModel stores the aspects tagged in each review
+type ReviewAspectModel struct {
+	DB DBTX
+}
+
+// TaggedReview is the set of aspect mentions found in one review
+type TaggedReview struct {
+	ReviewID int64
+	Mentions []aspects.Mention
+}
+
+// Replace stores the mentions of the given reviews, replacing their earlier
+// tags, and records the dictionary version that produced them. It bumps
+// updated_at so cached aspect summaries are revalidated.
+func (m ReviewAspectModel) Replace(ctx context.Context, version string, tagged []TaggedReview) error {
+	if len(tagged) == 0 {
+		return nil
+	}
+
+	reviewIDs := make([]int64, len(tagged))
+	var ids []int64
+	var names, polarities []string
+	var counts []int64
+	for i, review := range tagged {
+		reviewIDs[i] = review.ReviewID
+		for _, mention := range review.Mentions {
+			ids = append(ids, review.ReviewID)
+			names = append(names, mention.Aspect)
+			polarities = append(polarities, mention.Polarity)
+			counts = append(counts, int64(mention.Count))
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, `DELETE FROM review_aspects WHERE review_id = ANY($1)`, pq.Array(reviewIDs))
+	if err != nil {
+		return err
+	}
+
+	if len(ids) > 0 {
+		query := `INSERT INTO review_aspects (review_id, aspect, polarity, mentions)
+		SELECT * FROM unnest($1::bigint[], $2::text[], $3::text[], $4::integer[])`
+
+		_, err = m.DB.ExecContext(ctx, query, pq.Array(ids), pq.Array(names), pq.Array(polarities), pq.Array(counts))
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE reviews SET aspects_version = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = ANY($1) AND aspects_version IS DISTINCT FROM $2`
+
+	_, err = m.DB.ExecContext(ctx, query, pq.Array(reviewIDs), version)
+	return err
+}
+
+// ListUntagged returns up to limit reviews after afterID, in id order,
+// that were not tagged with the dictionary version. With all set every
+// review is returned.
+func (m ReviewAspectModel) ListUntagged(ctx context.Context, version string, all bool, afterID int64, limit int) ([]*ReviewText, error) {
+	query := `SELECT id, coalesce(review_positives, ''), coalesce(review_negatives, ''), coalesce(review_comments, '')
+	FROM reviews
+	WHERE id > $1
+	AND ($2::boolean OR aspects_version IS DISTINCT FROM $3)
+	ORDER BY id
+	LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, afterID, all, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := []*ReviewText{}
+	for rows.Next() {
+		var text ReviewText
+		if err := rows.Scan(&text.ID, &text.Positives, &text.Negatives, &text.Comments); err != nil {
+			return nil, err
+		}
+		texts = append(texts, &text)
+	}
+
+	return texts, rows.Err()
+}
+
+// AspectSummary counts the reviews of a hotel that mention an aspect in
+// their positives and in their negatives
+type AspectSummary struct {
+	Aspect   string  `json:"aspect"`
+	Positive int     `json:"positive"`
+	Negative int     `json:"negative"`
+	NetScore float64 `json:"net_score"` // (positive - negative) / (positive + negative), from -1 to 1
+}
+
+// HotelAspects summarises the aspects mentioned in a hotel's reviews within
+// the window, most complained about first
+func (a AnalyticsModel) HotelAspects(ctx context.Context, hotelID int64, window DateWindow) ([]AspectSummary, error) {
+	query := `SELECT ra.aspect,
+		count(*) FILTER (WHERE ra.polarity = 'positive'),
+		count(*) FILTER (WHERE ra.polarity = 'negative')
+	FROM review_aspects ra
+	JOIN reviews r ON r.id = ra.review_id
+	WHERE ` + statsWindow + `
+	GROUP BY ra.aspect
+	ORDER BY 3 DESC, 2 DESC, 1`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, hotelID, window.From, window.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []AspectSummary{}
+	for rows.Next() {
+		var summary AspectSummary
+		if err := rows.Scan(&summary.Aspect, &summary.Positive, &summary.Negative); err != nil {
+			return nil, err
+		}
+		summary.NetScore = float64(summary.Positive-summary.Negative) / float64(summary.Positive+summary.Negative)
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
@@ -3,6 +3,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -37,3 +38,48 @@ func (r ReviewGroupModel) CreateOrGet(name string) (*ReviewGroup, error) {
 	err = r.DB.QueryRowContext(ctx, insertQuery, name).Scan(&group.ID, &group.Name, &group.CreatedAt)
 	return group, err
 }
+
+// Resolve returns the review group a platform sends as externalID and name.
+// The external id is matched first, following a renamed group; without a
+// mapping the group is looked up by name, including the names platforms
+// renamed it to, and the id mapped onto it. An externalID of 0 means the
+// feed sent none.
+func (r ReviewGroupModel) Resolve(platform string, externalID int, name string) (*ReviewGroup, error) {
+	if externalID != 0 {
+		id, err := reviewGroupExternalIDs.resolve(r.DB, platform, externalID, name)
+		switch {
+		case err == nil:
+			return r.get(id)
+		case !errors.Is(err, ErrRecordNotFound):
+			return nil, err
+		}
+	}
+
+	var group *ReviewGroup
+	id, err := reviewGroupExternalIDs.byName(r.DB, name)
+	switch {
+	case err == nil:
+		group, err = r.get(id)
+	case errors.Is(err, ErrRecordNotFound):
+		group, err = r.CreateOrGet(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if externalID == 0 {
+		return group, nil
+	}
+
+	return group, reviewGroupExternalIDs.add(r.DB, platform, externalID, group.ID, name)
+}
+
+func (r ReviewGroupModel) get(id int) (*ReviewGroup, error) {
+	query := `SELECT id, name, created_at FROM review_groups WHERE id = $1`
+	group := &ReviewGroup{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&group.ID, &group.Name, &group.CreatedAt)
+	return group, err
+}
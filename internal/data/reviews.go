@@ -2,53 +2,80 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
 	"time"
+
+	"github.com/mahesh-singh/review-system/internal/langid"
+	"github.com/mahesh-singh/review-system/internal/validator"
 )
 
 type Review struct {
-	ID                      int64
-	HotelReviewID           int64
-	HotelID                 int64
-	ProviderID              int
-	Rating                  float64
-	CheckInMonthYear        string
-	EncryptedReviewData     string
-	FormattedRating         string
-	FormattedReviewDate     string
-	RatingText              string
-	ResponderName           string
-	ResponseDateText        string
-	ResponseTranslateSource string
-	ReviewComments          string
-	ReviewNegatives         string
-	ReviewPositives         string
-	ReviewProviderLogo      string
-	ReviewProviderText      string
-	ReviewTitle             string
-	TranslateSource         string
-	TranslateTarget         string
-	ReviewDate              time.Time
-	OriginalTitle           string
-	OriginalComment         string
-	FormattedResponseDate   string
-	IsShowReviewResponse    bool
+	ID                      int64     `json:"id"`
+	HotelReviewID           int64     `json:"hotel_review_id"`
+	HotelID                 int64     `json:"hotel_id"`
+	ProviderID              int       `json:"provider_id"`
+	Rating                  float64   `json:"rating"`
+	NormalizedRating        *float64  `json:"normalized_rating"` // Rating on 0-100 of the provider's registered scale
+	CheckInMonthYear        string    `json:"check_in_month_year"`
+	EncryptedReviewData     string    `json:"-"`
+	FormattedRating         string    `json:"formatted_rating"`
+	FormattedReviewDate     string    `json:"formatted_review_date"`
+	RatingText              string    `json:"rating_text"`
+	ResponderName           string    `json:"responder_name,omitempty"`
+	ResponseDateText        string    `json:"response_date_text,omitempty"`
+	ResponseTranslateSource string    `json:"response_translate_source,omitempty"`
+	ReviewComments          string    `json:"review_comments"`
+	ReviewNegatives         string    `json:"review_negatives"`
+	ReviewPositives         string    `json:"review_positives"`
+	ReviewProviderLogo      string    `json:"review_provider_logo"`
+	ReviewProviderText      string    `json:"review_provider_text"`
+	ReviewTitle             string    `json:"review_title"`
+	TranslateSource         string    `json:"translate_source,omitempty"`
+	TranslateTarget         string    `json:"translate_target,omitempty"`
+	ReviewDate              time.Time `json:"review_date"`
+	OriginalTitle           string    `json:"original_title,omitempty"`
+	OriginalComment         string    `json:"original_comment,omitempty"`
+	FormattedResponseDate   string    `json:"formatted_response_date,omitempty"`
+	IsShowReviewResponse    bool      `json:"is_show_review_response"`
 
 	// Reviewer Info (merged directly)
-	ReviewerCountryName     string
-	ReviewerDisplayName     string
-	ReviewerFlagName        string
-	ReviewerGroupName       string
-	ReviewerRoomTypeName    string
-	ReviewerCountryID       *int
-	ReviewerLengthOfStay    int
-	ReviewerGroupID         *int
-	ReviewerReviewCount     int
-	ReviewerIsExpert        bool
-	ReviewerShowGlobalIcon  bool
-	ReviewerShowReviewCount bool
-
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ReviewerCountryName     string `json:"reviewer_country_name"`
+	ReviewerDisplayName     string `json:"reviewer_display_name"`
+	ReviewerFlagName        string `json:"reviewer_flag_name"`
+	ReviewerGroupName       string `json:"reviewer_group_name"`
+	ReviewerRoomTypeName    string `json:"reviewer_room_type_name"`
+	RoomTypeID              *int   `json:"room_type_id"`
+	RoomCategory            string `json:"room_category,omitempty"` // Canonical category of the room type
+	ReviewerCountryID       *int   `json:"reviewer_country_id"`
+	ReviewerLengthOfStay    int    `json:"reviewer_length_of_stay"`
+	ReviewerGroupID         *int   `json:"reviewer_group_id"`
+	ReviewerReviewCount     int    `json:"reviewer_review_count"`
+	ReviewerIsExpert        bool   `json:"reviewer_is_expert"`
+	ReviewerShowGlobalIcon  bool   `json:"-"`
+	ReviewerShowReviewCount bool   `json:"-"`
+
+	// Lexicon sentiment of the review text from -1 to 1, nil until scored
+	SentimentScore   *float64 `json:"sentiment_score"`
+	SentimentVersion *int     `json:"-"`
+
+	// Near-duplicate detection, see package dedupe. ClusterID is the smallest
+	// review id of the review's cluster, nil until clustered.
+	TextSimhash    *int64 `json:"-"`
+	SimhashVersion *int   `json:"-"`
+	ClusterID      *int64 `json:"cluster_id"`
+
+	// ISO 639-1 code of the language the review was written in and the
+	// detector's confidence, see package langid. Nil when the text is too
+	// short, no language matches it clearly, or it isn't detected yet.
+	Language           *string  `json:"language"`
+	LanguageConfidence *float64 `json:"language_confidence"`
+	LanguageVersion    *int     `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type ReviewModel struct {
@@ -66,17 +93,30 @@ func (r ReviewModel) Create(review *Review) error {
 		reviewer_country_name, reviewer_display_name, reviewer_flag_name,
 		reviewer_group_name, reviewer_room_type_name, reviewer_country_id,
 		reviewer_length_of_stay, reviewer_group_id, reviewer_review_count,
-		reviewer_is_expert, reviewer_show_global_icon, reviewer_show_review_count
+		reviewer_is_expert, reviewer_show_global_icon, reviewer_show_review_count,
+		sentiment_score, sentiment_version, normalized_rating, text_simhash, simhash_version,
+		language, language_confidence, language_version, room_type_id
 	) VALUES (
 		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
 		$18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32,
-		$33, $34, $35, $36, $37
+		$33, $34, $35, $36, $37, $38, $39,
+		(SELECT least(round($4::numeric / p.rating_scale * 100, 1), 100) FROM providers p WHERE p.id = $3),
+		$40, $41, $42, $43, $44, $45
 	)
 	ON CONFLICT (hotel_review_id) DO UPDATE SET
 		rating = EXCLUDED.rating,
+		normalized_rating = EXCLUDED.normalized_rating,
 		review_comments = EXCLUDED.review_comments,
+		sentiment_score = EXCLUDED.sentiment_score,
+		sentiment_version = EXCLUDED.sentiment_version,
+		text_simhash = EXCLUDED.text_simhash,
+		simhash_version = EXCLUDED.simhash_version,
+		language = EXCLUDED.language,
+		language_confidence = EXCLUDED.language_confidence,
+		language_version = EXCLUDED.language_version,
+		room_type_id = EXCLUDED.room_type_id,
 		updated_at = CURRENT_TIMESTAMP
-	RETURNING id, created_at, updated_at`
+	RETURNING id, normalized_rating::float8, created_at, updated_at`
 
 	args := []interface{}{
 		review.HotelReviewID,
@@ -116,10 +156,432 @@ func (r ReviewModel) Create(review *Review) error {
 		review.ReviewerIsExpert,
 		review.ReviewerShowGlobalIcon,
 		review.ReviewerShowReviewCount,
+		review.SentimentScore,
+		review.SentimentVersion,
+		review.TextSimhash,
+		review.SimhashVersion,
+		review.Language,
+		review.LanguageConfidence,
+		review.LanguageVersion,
+		review.RoomTypeID,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	return r.DB.QueryRowContext(ctx, query, args...).Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+	return r.DB.QueryRowContext(ctx, query, args...).Scan(&review.ID, &review.NormalizedRating, &review.CreatedAt, &review.UpdatedAt)
+}
+
+// ReviewSummary holds headline review figures for a hotel
+type ReviewSummary struct {
+	TotalReviews     int        `json:"total_reviews"`
+	AverageRating    *float64   `json:"average_rating"` // Mean normalized rating, 0-100
+	ExpertReviews    int        `json:"expert_reviews"`
+	ReviewsResponded int        `json:"reviews_responded"`
+	FirstReviewDate  *time.Time `json:"first_review_date"`
+	LastReviewDate   *time.Time `json:"last_review_date"`
+}
+
+// GetSummary returns the review summary of a hotel. A hotel without reviews
+// gets a zero summary with null average and dates. Near-duplicates are
+// counted once.
+func (r ReviewModel) GetSummary(ctx context.Context, hotelID int64) (*ReviewSummary, error) {
+	query := `SELECT count(*),
+		avg(r.normalized_rating)::float8,
+		count(*) FILTER (WHERE r.reviewer_is_expert),
+		count(*) FILTER (WHERE coalesce(r.responder_name, '') <> ''),
+		min(r.review_date),
+		max(r.review_date)
+	FROM reviews r
+	WHERE r.hotel_id = $1 AND ` + countedReview
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	summary := &ReviewSummary{}
+	err := r.DB.QueryRowContext(ctx, query, hotelID).Scan(
+		&summary.TotalReviews,
+		&summary.AverageRating,
+		&summary.ExpertReviews,
+		&summary.ReviewsResponded,
+		&summary.FirstReviewDate,
+		&summary.LastReviewDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// ReviewSortSafelist lists the sort values accepted by ReviewModel.List
+var ReviewSortSafelist = []string{"review_date", "-review_date", "rating", "-rating"}
+
+// ReviewFilter narrows ReviewModel.List. Nil and empty fields are not applied.
+type ReviewFilter struct {
+	HotelID       int64
+	ProviderID    *int
+	MinRating     *float64 // Normalized rating, 0-100
+	MaxRating     *float64
+	From          *time.Time // review_date >= From
+	To            *time.Time // review_date < To
+	CountryID     *int
+	ReviewGroupID *int
+	RoomType      string
+	RoomCategory  string // Canonical room type category, e.g. Deluxe Double
+	ExpertOnly    bool
+	HasResponse   *bool
+	MinSentiment  *float64
+	MaxSentiment  *float64
+	Language      string // ISO 639-1 code
+	Sort          string
+	Cursor        string
+	PageSize      int
+}
+
+func ValidateReviewFilter(v *validator.Validator, f ReviewFilter) {
+	v.Check(f.PageSize > 0, "page_size", "must be greater than zero")
+	v.Check(f.PageSize <= 100, "page_size", "must be a maximum of 100")
+	v.Check(validator.PermittedValue(f.Sort, ReviewSortSafelist...), "sort", "invalid sort value")
+
+	if f.MinRating != nil {
+		v.Check(*f.MinRating >= 0 && *f.MinRating <= 100, "min_rating", "must be between 0 and 100")
+	}
+	if f.MaxRating != nil {
+		v.Check(*f.MaxRating >= 0 && *f.MaxRating <= 100, "max_rating", "must be between 0 and 100")
+	}
+	if f.MinRating != nil && f.MaxRating != nil {
+		v.Check(*f.MinRating <= *f.MaxRating, "min_rating", "must not be greater than max_rating")
+	}
+	if f.From != nil && f.To != nil {
+		v.Check(f.From.Before(*f.To), "from", "must be before to")
+	}
+	if f.MinSentiment != nil {
+		v.Check(*f.MinSentiment >= -1 && *f.MinSentiment <= 1, "min_sentiment", "must be between -1 and 1")
+	}
+	if f.MaxSentiment != nil {
+		v.Check(*f.MaxSentiment >= -1 && *f.MaxSentiment <= 1, "max_sentiment", "must be between -1 and 1")
+	}
+	if f.MinSentiment != nil && f.MaxSentiment != nil {
+		v.Check(*f.MinSentiment <= *f.MaxSentiment, "min_sentiment", "must not be greater than max_sentiment")
+	}
+
+	ValidateLanguage(v, "language", f.Language)
+}
+
+// ValidateLanguage checks that language is empty or a code langid can
+// detect
+func ValidateLanguage(v *validator.Validator, key, language string) {
+	if language != "" {
+		v.Check(validator.PermittedValue(language, langid.Languages()...), key, "must be a supported ISO 639-1 language code")
+	}
+}
+
+// reviewSortExpr maps a sort value to its SQL expression and the Postgres
+// type of the cursor value. review_date and normalized_rating are nullable,
+// so NULLs sort as -infinity and -1 to keep the keyset total. rating sorts
+// by the normalized rating, as raw ratings of different providers are on
+// different scales.
+func reviewSortExpr(sort string) (expr, valueType string) {
+	switch strings.TrimPrefix(sort, "-") {
+	case "review_date":
+		return "coalesce(review_date, '-infinity'::timestamp)", "timestamp"
+	case "rating":
+		return "coalesce(normalized_rating, -1)", "numeric"
+	}
+
+	panic("unsafe sort parameter: " + sort)
+}
+
+// List returns one page of a hotel's reviews using keyset pagination on the
+// sort column and id. The returned cursor is empty on the last page.
+func (r ReviewModel) List(ctx context.Context, filter ReviewFilter) ([]*Review, string, error) {
+	sortExpr, valueType := reviewSortExpr(filter.Sort)
+
+	direction, comparison := "ASC", ">"
+	if strings.HasPrefix(filter.Sort, "-") {
+		direction, comparison = "DESC", "<"
+	}
+
+	var cursorValue *string
+	var cursorID *int64
+	if filter.Cursor != "" {
+		cursor, err := DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if cursor.Sort != filter.Sort {
+			return nil, "", ErrInvalidCursor
+		}
+		cursorValue, cursorID = &cursor.Value, &cursor.ID
+	}
+
+	query := fmt.Sprintf(`SELECT id, hotel_review_id, hotel_id, provider_id, rating, normalized_rating::float8, check_in_month_year,
+		formatted_rating, formatted_review_date, rating_text, responder_name, response_date_text,
+		response_translate_source, review_comments, review_negatives, review_positives,
+		review_provider_logo, review_provider_text, review_title, translate_source, translate_target,
+		review_date, original_title, original_comment, formatted_response_date, is_show_review_response,
+		reviewer_country_name, reviewer_display_name, reviewer_flag_name, reviewer_group_name,
+		reviewer_room_type_name, room_type_id,
+		coalesce((SELECT rt.category FROM room_types rt WHERE rt.id = reviews.room_type_id), ''),
+		reviewer_country_id, reviewer_length_of_stay, reviewer_group_id,
+		reviewer_review_count, reviewer_is_expert, sentiment_score, cluster_id,
+		language, language_confidence::float8, created_at, updated_at,
+		%[1]s::text
+	FROM reviews
+	WHERE hotel_id = $1
+	AND ($2::int IS NULL OR provider_id = $2)
+	AND ($3::numeric IS NULL OR normalized_rating >= $3)
+	AND ($4::numeric IS NULL OR normalized_rating <= $4)
+	AND ($5::timestamp IS NULL OR review_date >= $5)
+	AND ($6::timestamp IS NULL OR review_date < $6)
+	AND ($7::int IS NULL OR reviewer_country_id = $7)
+	AND ($8::int IS NULL OR reviewer_group_id = $8)
+	AND ($9::text = '' OR lower(reviewer_room_type_name) = lower($9))
+	AND (NOT $10::boolean OR reviewer_is_expert)
+	AND ($11::boolean IS NULL OR (coalesce(responder_name, '') <> '') = $11)
+	AND ($12::numeric IS NULL OR sentiment_score >= $12)
+	AND ($13::numeric IS NULL OR sentiment_score <= $13)
+	AND ($14::text = '' OR language = $14)
+	AND ($15::text = '' OR room_type_id IN (
+		SELECT rt.id FROM room_types rt WHERE rt.hotel_id = $1 AND lower(rt.category) = lower($15)
+	))
+	AND ($16::text IS NULL OR (%[1]s, id) %[2]s ($16::%[3]s, $17::bigint))
+	ORDER BY %[1]s %[4]s, id %[4]s
+	LIMIT $18`, sortExpr, comparison, valueType, direction)
+
+	args := []interface{}{
+		filter.HotelID,
+		filter.ProviderID,
+		filter.MinRating,
+		filter.MaxRating,
+		filter.From,
+		filter.To,
+		filter.CountryID,
+		filter.ReviewGroupID,
+		filter.RoomType,
+		filter.ExpertOnly,
+		filter.HasResponse,
+		filter.MinSentiment,
+		filter.MaxSentiment,
+		filter.Language,
+		filter.RoomCategory,
+		cursorValue,
+		cursorID,
+		filter.PageSize + 1, // One extra row tells us whether there is a next page
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	reviews := []*Review{}
+	sortValues := []string{}
+
+	for rows.Next() {
+		var review Review
+		var reviewDate sql.NullTime
+		var sortValue string
+
+		err := rows.Scan(
+			&review.ID,
+			&review.HotelReviewID,
+			&review.HotelID,
+			&review.ProviderID,
+			&review.Rating,
+			&review.NormalizedRating,
+			&review.CheckInMonthYear,
+			&review.FormattedRating,
+			&review.FormattedReviewDate,
+			&review.RatingText,
+			&review.ResponderName,
+			&review.ResponseDateText,
+			&review.ResponseTranslateSource,
+			&review.ReviewComments,
+			&review.ReviewNegatives,
+			&review.ReviewPositives,
+			&review.ReviewProviderLogo,
+			&review.ReviewProviderText,
+			&review.ReviewTitle,
+			&review.TranslateSource,
+			&review.TranslateTarget,
+			&reviewDate,
+			&review.OriginalTitle,
+			&review.OriginalComment,
+			&review.FormattedResponseDate,
+			&review.IsShowReviewResponse,
+			&review.ReviewerCountryName,
+			&review.ReviewerDisplayName,
+			&review.ReviewerFlagName,
+			&review.ReviewerGroupName,
+			&review.ReviewerRoomTypeName,
+			&review.RoomTypeID,
+			&review.RoomCategory,
+			&review.ReviewerCountryID,
+			&review.ReviewerLengthOfStay,
+			&review.ReviewerGroupID,
+			&review.ReviewerReviewCount,
+			&review.ReviewerIsExpert,
+			&review.SentimentScore,
+			&review.ClusterID,
+			&review.Language,
+			&review.LanguageConfidence,
+			&review.CreatedAt,
+			&review.UpdatedAt,
+			&sortValue,
+		)
+		if err != nil {
+			return nil, "", err
+		}
+		review.ReviewDate = reviewDate.Time
+
+		reviews = append(reviews, &review)
+		sortValues = append(sortValues, sortValue)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(reviews) > filter.PageSize {
+		reviews = reviews[:filter.PageSize]
+		last := reviews[len(reviews)-1]
+		nextCursor = Cursor{
+			Sort:  filter.Sort,
+			Value: sortValues[len(reviews)-1],
+			ID:    last.ID,
+		}.Encode()
+	}
+
+	return reviews, nextCursor, nil
+}
+
+// ReviewSearchResult is a review matched by ReviewModel.Search with its rank
+// and a highlighted snippet of the matching text. The snippet is HTML
+// escaped, with hits wrapped in <mark></mark>.
+type ReviewSearchResult struct {
+	ID                  int64     `json:"id"`
+	HotelReviewID       int64     `json:"hotel_review_id"`
+	HotelID             int64     `json:"hotel_id"`
+	ProviderID          int       `json:"provider_id"`
+	Rating              float64   `json:"rating"`
+	ReviewTitle         string    `json:"review_title"`
+	ReviewDate          time.Time `json:"review_date"`
+	ReviewerDisplayName string    `json:"reviewer_display_name"`
+	Rank                float64   `json:"rank"`
+	Snippet             string    `json:"snippet"`
+}
+
+// ReviewSearch holds the input of ReviewModel.Search
+type ReviewSearch struct {
+	Query    string
+	Language string // Language code used to stem the query, e.g. 'en'
+	HotelID  *int64
+	Filters
+}
+
+func ValidateReviewSearch(v *validator.Validator, search ReviewSearch) {
+	v.Check(strings.TrimSpace(search.Query) != "", "q", "must be provided")
+	v.Check(len(search.Query) <= 500, "q", "must not be more than 500 bytes long")
+
+	ValidateFilters(v, search.Filters)
+}
+
+// snippetMarks turns the control characters ts_headline is told to wrap
+// hits in into <mark> tags, once the review text around them is escaped
+var snippetMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+// highlightSnippet escapes a ts_headline snippet for HTML, keeping its hits
+// highlighted. Review text is untrusted, so ts_headline can't be given the
+// tags directly.
+func highlightSnippet(snippet string) string {
+	return snippetMarks.Replace(html.EscapeString(snippet))
+}
+
+// Search runs a full-text query over review titles, comments, positives and
+// negatives. The query is stemmed in the requested language and also matched
+// as plain words, so reviews indexed with a different configuration are
+// still found. Matches in the title rank highest.
+func (r ReviewModel) Search(ctx context.Context, search ReviewSearch) ([]*ReviewSearchResult, Metadata, error) {
+	query := `WITH q AS (
+		SELECT websearch_to_tsquery(review_ts_config($2), $1) || websearch_to_tsquery('simple', $1) AS query
+	)
+	SELECT ranked.total, ranked.id, ranked.hotel_review_id, ranked.hotel_id, ranked.provider_id,
+		ranked.rating, ranked.review_title, ranked.review_date, ranked.reviewer_display_name, ranked.rank,
+		ts_headline(review_ts_config(ranked.translate_source),
+			translate(concat_ws(' ', ranked.review_title, ranked.review_comments, ranked.review_positives, ranked.review_negatives),
+				chr(2) || chr(3), ''),
+			q.query,
+			'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=2, MaxWords=30, MinWords=10')
+	FROM (
+		SELECT count(*) OVER() AS total, r.id, r.hotel_review_id, r.hotel_id, r.provider_id, r.rating,
+			r.review_title, r.review_date, r.reviewer_display_name, r.translate_source,
+			r.review_comments, r.review_positives, r.review_negatives,
+			ts_rank_cd(r.search_vector, q.query) AS rank
+		FROM reviews r, q
+		WHERE r.search_vector @@ q.query
+		AND ($3::bigint IS NULL OR r.hotel_id = $3)
+		ORDER BY rank DESC, r.id DESC
+		LIMIT $4 OFFSET $5
+	) ranked, q
+	ORDER BY ranked.rank DESC, ranked.id DESC`
+
+	args := []interface{}{
+		search.Query,
+		search.Language,
+		search.HotelID,
+		search.limit(),
+		search.offset(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	results := []*ReviewSearchResult{}
+
+	for rows.Next() {
+		var result ReviewSearchResult
+		var reviewDate sql.NullTime
+
+		err := rows.Scan(
+			&totalRecords,
+			&result.ID,
+			&result.HotelReviewID,
+			&result.HotelID,
+			&result.ProviderID,
+			&result.Rating,
+			&result.ReviewTitle,
+			&reviewDate,
+			&result.ReviewerDisplayName,
+			&result.Rank,
+			&result.Snippet,
+		)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+		result.ReviewDate = reviewDate.Time
+		result.Snippet = highlightSnippet(result.Snippet)
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	metadata := calculateMetadata(totalRecords, search.Page, search.PageSize)
+
+	return results, metadata, nil
 }
@@ -0,0 +1,26 @@
+package data
+
+import "testing"
+
+func TestHighlightSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{"plain", "a clean room", "a clean room"},
+		{"hit", "a \x02clean\x03 room", "a <mark>clean</mark> room"},
+		{"two hits", "\x02clean\x03 and \x02quiet\x03", "<mark>clean</mark> and <mark>quiet</mark>"},
+		{"markup in review", "<script>alert(1)</script> \x02clean\x03", "&lt;script&gt;alert(1)&lt;/script&gt; <mark>clean</mark>"},
+		{"tags typed by the reviewer", "<mark>fake</mark>", "&lt;mark&gt;fake&lt;/mark&gt;"},
+		{"quotes and ampersand", "\"B&B\" it's \x02fine\x03", "&#34;B&amp;B&#34; it&#39;s <mark>fine</mark>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.snippet); got != tt.want {
+				t.Errorf("highlightSnippet(%q) = %q, want %q", tt.snippet, got, tt.want)
+			}
+		})
+	}
+}
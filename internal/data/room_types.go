@@ -0,0 +1,405 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RoomType is a hotel's room type as one provider names it, with the
+// canonical category the name maps to
+type RoomType struct {
+	ID                 int       `json:"id"`
+	HotelID            int64     `json:"hotel_id"`
+	ProviderID         int       `json:"provider_id"`
+	ProviderRoomTypeID int       `json:"provider_room_type_id"` // 0 when the feed has none
+	Name               string    `json:"name"`
+	Category           string    `json:"category"`
+	RulesVersion       string    `json:"-"`
+	ReviewCount        int       `json:"review_count,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type RoomTypeModel struct {
+	DB DBTX
+}
+
+// CreateOrGet looks up the room type by hotel, provider and provider room
+// type id, falling back to the name when the feed sent no id or the id is
+// new, and creates it with roomType's category when neither matches. The
+// stored id and category are written back to roomType.
+//
+// A room type found by its id under another name was renamed by the
+// provider: it takes the new name and category, and the change is recorded.
+// Feeds without the id that still send the old name find it by that name.
+// A room type found by name keeps its category until `room-types backfill`
+// recategorizes it.
+func (m RoomTypeModel) CreateOrGet(roomType *RoomType) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	resolve := m.resolve
+	if roomType.ProviderRoomTypeID == 0 {
+		resolve = m.resolveName
+	}
+	found, err := resolve(ctx, roomType)
+	if found || err != nil {
+		return err
+	}
+
+	query := `INSERT INTO room_types (hotel_id, provider_id, provider_room_type_id, name, category, rules_version)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (hotel_id, provider_id, provider_room_type_id, name) DO UPDATE SET name = EXCLUDED.name
+	RETURNING id, category, rules_version, created_at, updated_at`
+
+	args := []interface{}{
+		roomType.HotelID,
+		roomType.ProviderID,
+		roomType.ProviderRoomTypeID,
+		roomType.Name,
+		roomType.Category,
+		roomType.RulesVersion,
+	}
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(
+		&roomType.ID,
+		&roomType.Category,
+		&roomType.RulesVersion,
+		&roomType.CreatedAt,
+		&roomType.UpdatedAt,
+	)
+}
+
+// resolve finds roomType by its provider room type id, renaming it when the
+// name changed, or adopts a room type of the same name that has no id yet.
+// It reports whether one was found.
+func (m RoomTypeModel) resolve(ctx context.Context, roomType *RoomType) (bool, error) {
+	query := `SELECT id, name, category, rules_version, created_at, updated_at FROM room_types
+	WHERE hotel_id = $1 AND provider_id = $2 AND provider_room_type_id = $3`
+
+	var stored RoomType
+	err := m.DB.QueryRowContext(ctx, query, roomType.HotelID, roomType.ProviderID, roomType.ProviderRoomTypeID).Scan(
+		&stored.ID,
+		&stored.Name,
+		&stored.Category,
+		&stored.RulesVersion,
+		&stored.CreatedAt,
+		&stored.UpdatedAt,
+	)
+
+	switch {
+	case err == nil && stored.Name == roomType.Name:
+		roomType.ID = stored.ID
+		roomType.Category = stored.Category
+		roomType.RulesVersion = stored.RulesVersion
+		roomType.CreatedAt = stored.CreatedAt
+		roomType.UpdatedAt = stored.UpdatedAt
+		return true, nil
+
+	case err == nil:
+		query = `UPDATE room_types
+		SET name = $2, category = $3, rules_version = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING id, category, rules_version, created_at, updated_at`
+		err = m.DB.QueryRowContext(ctx, query, stored.ID, roomType.Name, roomType.Category, roomType.RulesVersion).Scan(
+			&roomType.ID,
+			&roomType.Category,
+			&roomType.RulesVersion,
+			&roomType.CreatedAt,
+			&roomType.UpdatedAt,
+		)
+		if err != nil {
+			return true, err
+		}
+
+		var platform string
+		err = m.DB.QueryRowContext(ctx, `SELECT platform FROM hotels WHERE hotel_id = $1`, roomType.HotelID).Scan(&platform)
+		if err != nil {
+			return true, err
+		}
+
+		return true, recordNameChange(ctx, m.DB, LookupNameChange{
+			Lookup:     LookupRoomType,
+			LookupID:   stored.ID,
+			Platform:   platform,
+			ExternalID: roomType.ProviderRoomTypeID,
+			OldName:    stored.Name,
+			NewName:    roomType.Name,
+		})
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, err
+	}
+
+	query = `UPDATE room_types SET provider_room_type_id = $3
+	WHERE hotel_id = $1 AND provider_id = $2 AND provider_room_type_id = 0 AND name = $4
+	RETURNING id, category, rules_version, created_at, updated_at`
+	err = m.DB.QueryRowContext(ctx, query, roomType.HotelID, roomType.ProviderID, roomType.ProviderRoomTypeID, roomType.Name).Scan(
+		&roomType.ID,
+		&roomType.Category,
+		&roomType.RulesVersion,
+		&roomType.CreatedAt,
+		&roomType.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// resolveName finds a room type without a provider room type id by its
+// name, or a room type the provider renamed from that name. It reports
+// whether one was found.
+func (m RoomTypeModel) resolveName(ctx context.Context, roomType *RoomType) (bool, error) {
+	query := `SELECT id, category, rules_version, created_at, updated_at
+	FROM room_types
+	WHERE hotel_id = $1 AND provider_id = $2
+	AND ((provider_room_type_id = 0 AND name = $3) OR id IN (` + renamedFrom + `))
+	ORDER BY name = $3 DESC, updated_at DESC
+	LIMIT 1`
+
+	err := m.DB.QueryRowContext(ctx, query, roomType.HotelID, roomType.ProviderID, roomType.Name, LookupRoomType).Scan(
+		&roomType.ID,
+		&roomType.Category,
+		&roomType.RulesVersion,
+		&roomType.CreatedAt,
+		&roomType.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// renamedFrom selects the room types renamed from the name $3, with the
+// room type lookup kind as $4
+const renamedFrom = `SELECT lookup_id FROM lookup_name_changes WHERE lookup = $4 AND old_name = $3`
+
+// GetByName returns the room type a provider gave name for a hotel,
+// preferring one with a provider room type id, then one the provider
+// renamed from name. Reviews imported before room types existed have only
+// the name to go on.
+func (m RoomTypeModel) GetByName(hotelID int64, providerID int, name string) (*RoomType, error) {
+	query := `SELECT id, hotel_id, provider_id, provider_room_type_id, name, category, rules_version, created_at, updated_at
+	FROM room_types
+	WHERE hotel_id = $1 AND provider_id = $2
+	AND (name = $3 OR id IN (` + renamedFrom + `))
+	ORDER BY name = $3 DESC, provider_room_type_id DESC, updated_at DESC
+	LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var roomType RoomType
+	err := m.DB.QueryRowContext(ctx, query, hotelID, providerID, name, LookupRoomType).Scan(
+		&roomType.ID,
+		&roomType.HotelID,
+		&roomType.ProviderID,
+		&roomType.ProviderRoomTypeID,
+		&roomType.Name,
+		&roomType.Category,
+		&roomType.RulesVersion,
+		&roomType.CreatedAt,
+		&roomType.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &roomType, nil
+}
+
+// GetForHotel returns the room types of a hotel with their number of
+// reviews, by category and name
+func (m RoomTypeModel) GetForHotel(ctx context.Context, hotelID int64) ([]*RoomType, error) {
+	query := `SELECT rt.id, rt.hotel_id, rt.provider_id, rt.provider_room_type_id, rt.name, rt.category,
+		rt.rules_version, rt.created_at, rt.updated_at,
+		(SELECT count(*) FROM reviews r WHERE r.room_type_id = rt.id)
+	FROM room_types rt
+	WHERE rt.hotel_id = $1
+	ORDER BY rt.category, rt.name, rt.id`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	return m.query(ctx, query, hotelID)
+}
+
+// ListOutdated returns up to limit room types after afterID, in id order,
+// that were not categorized by version. With all set every room type is
+// returned.
+func (m RoomTypeModel) ListOutdated(ctx context.Context, version string, all bool, afterID, limit int) ([]*RoomType, error) {
+	query := `SELECT rt.id, rt.hotel_id, rt.provider_id, rt.provider_room_type_id, rt.name, rt.category,
+		rt.rules_version, rt.created_at, rt.updated_at, 0
+	FROM room_types rt
+	WHERE rt.id > $1
+	AND ($2::boolean OR rt.rules_version <> $3)
+	ORDER BY rt.id
+	LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return m.query(ctx, query, afterID, all, version, limit)
+}
+
+func (m RoomTypeModel) query(ctx context.Context, query string, args ...interface{}) ([]*RoomType, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roomTypes := []*RoomType{}
+	for rows.Next() {
+		var roomType RoomType
+		err := rows.Scan(
+			&roomType.ID,
+			&roomType.HotelID,
+			&roomType.ProviderID,
+			&roomType.ProviderRoomTypeID,
+			&roomType.Name,
+			&roomType.Category,
+			&roomType.RulesVersion,
+			&roomType.CreatedAt,
+			&roomType.UpdatedAt,
+			&roomType.ReviewCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		roomTypes = append(roomTypes, &roomType)
+	}
+
+	return roomTypes, rows.Err()
+}
+
+// SetCategories stores the categories computed by version, keyed by room
+// type id, and returns the number of room types whose category changed. The
+// reviews of those room types get their updated_at bumped so cached
+// listings and stats are revalidated.
+func (m RoomTypeModel) SetCategories(ctx context.Context, version string, categories map[int]string) (int, error) {
+	if len(categories) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(categories))
+	values := make([]string, 0, len(categories))
+	for id, category := range categories {
+		ids = append(ids, int64(id))
+		values = append(values, category)
+	}
+
+	query := `WITH c AS (
+		SELECT * FROM unnest($1::int[], $2::text[]) AS c(id, category)
+	),
+	recategorized AS (
+		UPDATE room_types rt
+		SET category = c.category, rules_version = $3, updated_at = CURRENT_TIMESTAMP
+		FROM c
+		WHERE rt.id = c.id AND rt.category <> c.category
+		RETURNING rt.id
+	),
+	versioned AS (
+		UPDATE room_types rt
+		SET rules_version = $3
+		FROM c
+		WHERE rt.id = c.id AND rt.category = c.category
+	),
+	touched AS (
+		UPDATE reviews r
+		SET updated_at = CURRENT_TIMESTAMP
+		FROM recategorized rc
+		WHERE r.room_type_id = rc.id
+	)
+	SELECT count(*) FROM recategorized`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var changed int
+	err := m.DB.QueryRowContext(ctx, query, pq.Array(ids), pq.Array(values), version).Scan(&changed)
+	return changed, err
+}
+
+// ReviewRoomType is the room type name of one review and the room type it
+// links to
+type ReviewRoomType struct {
+	ID                 int64
+	HotelID            int64
+	ProviderID         int
+	RoomTypeName       string
+	ProviderRoomTypeID int // Not kept on reviews, so 0 for existing ones
+	RoomTypeID         int
+}
+
+// ListUnlinkedRoomTypes returns up to limit reviews after afterID, in id
+// order, that have a room type name but no room type
+func (r ReviewModel) ListUnlinkedRoomTypes(ctx context.Context, afterID int64, limit int) ([]*ReviewRoomType, error) {
+	query := `SELECT id, hotel_id, provider_id, reviewer_room_type_name
+	FROM reviews
+	WHERE id > $1
+	AND room_type_id IS NULL
+	AND coalesce(reviewer_room_type_name, '') <> ''
+	ORDER BY id
+	LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*ReviewRoomType{}
+	for rows.Next() {
+		var review ReviewRoomType
+		if err := rows.Scan(&review.ID, &review.HotelID, &review.ProviderID, &review.RoomTypeName); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, &review)
+	}
+
+	return reviews, rows.Err()
+}
+
+// SetRoomTypes links reviews to their room types and returns the number of
+// reviews updated
+func (r ReviewModel) SetRoomTypes(ctx context.Context, links []*ReviewRoomType) (int64, error) {
+	if len(links) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(links))
+	roomTypeIDs := make([]int64, len(links))
+	for i, link := range links {
+		ids[i] = link.ID
+		roomTypeIDs[i] = int64(link.RoomTypeID)
+	}
+
+	query := `UPDATE reviews r
+	SET room_type_id = l.room_type_id, updated_at = CURRENT_TIMESTAMP
+	FROM unnest($1::bigint[], $2::int[]) AS l(id, room_type_id)
+	WHERE r.id = l.id`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, pq.Array(ids), pq.Array(roomTypeIDs))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
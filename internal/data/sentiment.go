@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ReviewText is the free text of a review that enrichment stages score
+type ReviewText struct {
+	ID        int64
+	Positives string
+	Negatives string
+	Comments  string
+
+	OriginalComment string // Only read by ListUndetected
+}
+
+// ReviewSentiment is the sentiment score of one review
+type ReviewSentiment struct {
+	ID    int64
+	Score float64
+}
+
+// ListUnscored returns up to limit reviews after afterID, in id order, whose
+// sentiment was not scored by version. With all set every review is
+// returned, for rescoring after a change that keeps the version.
+func (r ReviewModel) ListUnscored(ctx context.Context, version int, all bool, afterID int64, limit int) ([]*ReviewText, error) {
+	query := `SELECT id, coalesce(review_positives, ''), coalesce(review_negatives, ''), coalesce(review_comments, '')
+	FROM reviews
+	WHERE id > $1
+	AND ($2::boolean OR sentiment_version IS DISTINCT FROM $3)
+	ORDER BY id
+	LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query, afterID, all, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	texts := []*ReviewText{}
+	for rows.Next() {
+		var text ReviewText
+		if err := rows.Scan(&text.ID, &text.Positives, &text.Negatives, &text.Comments); err != nil {
+			return nil, err
+		}
+		texts = append(texts, &text)
+	}
+
+	return texts, rows.Err()
+}
+
+// SetSentiment stores the scores computed by version and returns the number
+// of reviews updated. updated_at is bumped so cached responses that include
+// the old score are revalidated.
+func (r ReviewModel) SetSentiment(ctx context.Context, version int, scores []ReviewSentiment) (int64, error) {
+	if len(scores) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, len(scores))
+	values := make([]float64, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ID
+		values[i] = score.Score
+	}
+
+	query := `UPDATE reviews r
+	SET sentiment_score = s.score, sentiment_version = $3, updated_at = CURRENT_TIMESTAMP
+	FROM unnest($1::bigint[], $2::numeric[]) AS s(id, score)
+	WHERE r.id = s.id`
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, query, pq.Array(ids), pq.Array(values), version)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
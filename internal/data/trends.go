@@ -0,0 +1,149 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// Trend intervals and splits
+const (
+	TrendMonth = "month"
+	TrendWeek  = "week"
+
+	TrendSplitNone        = ""
+	TrendSplitProvider    = "provider"
+	TrendSplitReviewGroup = "review_group"
+)
+
+// TrendQuery selects the normalized rating trend of one hotel. Reviews are bucketed
+// on review_date by Interval and split into one series per provider or
+// reviewer group, or a single "all" series.
+type TrendQuery struct {
+	HotelID  int64
+	Interval string
+	SplitBy  string
+	Window   DateWindow
+	Rolling  int // Number of periods in the rolling average, including the current one
+}
+
+// TrendPoint is one period of a series. Periods without reviews are kept so
+// every series is continuous; their means are null.
+type TrendPoint struct {
+	Period      time.Time `json:"period"`
+	ReviewCount int       `json:"review_count"`
+	MeanRating  *float64  `json:"mean_rating"`  // Mean normalized rating, 0-100
+	RollingMean *float64  `json:"rolling_mean"` // Review weighted mean over the last Rolling periods
+	Delta       *float64  `json:"delta"`        // Change in mean rating from the previous period
+}
+
+type TrendSeries struct {
+	Key    string       `json:"key"`
+	Points []TrendPoint `json:"points"`
+}
+
+type RatingTrend struct {
+	HotelID  int64         `json:"hotel_id"`
+	Interval string        `json:"interval"`
+	SplitBy  string        `json:"split_by,omitempty"`
+	Rolling  int           `json:"rolling"`
+	Window   DateWindow    `json:"window"`
+	Series   []TrendSeries `json:"series"`
+}
+
+func ValidateTrendQuery(v *validator.Validator, q TrendQuery) {
+	v.Check(validator.PermittedValue(q.Interval, TrendMonth, TrendWeek), "interval", "must be month or week")
+	v.Check(validator.PermittedValue(q.SplitBy, TrendSplitNone, TrendSplitProvider, TrendSplitReviewGroup), "split", "must be provider or review_group")
+	v.Check(q.Rolling >= 1 && q.Rolling <= 24, "rolling", "must be between 1 and 24")
+}
+
+// trendSource returns the query producing (period, key, review_count,
+// rating_sum) rows for a split. Totals and provider splits read the daily
+// rollup; reviewer groups aren't rolled up, so that split reads reviews.
+func trendSource(splitBy string) string {
+	switch splitBy {
+	case TrendSplitReviewGroup:
+		return `SELECT date_trunc($4::text, r.review_date) AS period, ` + breakdownReviewGroup + ` AS key,
+			count(*) AS review_count, sum(r.normalized_rating) AS rating_sum
+		FROM reviews r
+		WHERE ` + statsWindow + ` AND r.review_date IS NOT NULL
+		GROUP BY 1, 2`
+	default:
+		key := `'all'`
+		if splitBy == TrendSplitProvider {
+			key = breakdownProvider
+		}
+		return `SELECT date_trunc($4::text, d.day::timestamp) AS period, ` + key + ` AS key,
+			sum(d.review_count) AS review_count, sum(d.normalized_rating_sum) AS rating_sum
+		FROM hotel_daily_stats d
+		LEFT JOIN providers p ON p.id = d.provider_id
+		WHERE d.hotel_id = $1
+		AND ($2::timestamp IS NULL OR d.day >= $2::timestamp)
+		AND ($3::timestamp IS NULL OR d.day < $3::timestamp)
+		GROUP BY 1, 2`
+	}
+}
+
+// RatingTrend returns the average rating and review volume per period, with
+// a rolling average and the change from the previous period
+func (a AnalyticsModel) RatingTrend(ctx context.Context, q TrendQuery) (*RatingTrend, error) {
+	query := fmt.Sprintf(`WITH buckets AS (
+		%s
+	),
+	periods AS (
+		SELECT key, generate_series(min(period), max(period), ('1 ' || $4::text)::interval) AS period
+		FROM buckets
+		GROUP BY key
+	),
+	points AS (
+		SELECT p.key, p.period,
+			coalesce(b.review_count, 0) AS review_count,
+			coalesce(b.rating_sum, 0) AS rating_sum,
+			(b.rating_sum / nullif(b.review_count, 0))::float8 AS mean_rating
+		FROM periods p
+		LEFT JOIN buckets b ON b.key = p.key AND b.period = p.period
+	)
+	SELECT key, period, review_count, mean_rating,
+		(sum(rating_sum) OVER w / nullif(sum(review_count) OVER w, 0))::float8,
+		mean_rating - lag(mean_rating) OVER (PARTITION BY key ORDER BY period)
+	FROM points
+	WINDOW w AS (PARTITION BY key ORDER BY period ROWS BETWEEN $5::int PRECEDING AND CURRENT ROW)
+	ORDER BY key, period`, trendSource(q.SplitBy))
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, q.HotelID, q.Window.From, q.Window.To, q.Interval, q.Rolling-1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trend := &RatingTrend{
+		HotelID:  q.HotelID,
+		Interval: q.Interval,
+		SplitBy:  q.SplitBy,
+		Rolling:  q.Rolling,
+		Window:   q.Window,
+		Series:   []TrendSeries{},
+	}
+
+	for rows.Next() {
+		var key string
+		var point TrendPoint
+		err := rows.Scan(&key, &point.Period, &point.ReviewCount, &point.MeanRating, &point.RollingMean, &point.Delta)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(trend.Series); n == 0 || trend.Series[n-1].Key != key {
+			trend.Series = append(trend.Series, TrendSeries{Key: key, Points: []TrendPoint{}})
+		}
+		series := &trend.Series[len(trend.Series)-1]
+		series.Points = append(series.Points, point)
+	}
+
+	return trend, rows.Err()
+}
@@ -0,0 +1,212 @@
+// Package dedupe finds near-duplicate reviews: the same guest review listed
+// by several providers, or scraped again under a new hotelReviewId. Review
+// text is reduced to a 64-bit SimHash of its character shingles, and two
+// reviews of a hotel are duplicates when their hashes differ in at most a
+// few bits, their reviewer names agree and they were posted within a few
+// days.
+package dedupe
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mahesh-singh/review-system/internal/validator"
+)
+
+// Version identifies the signature algorithm. It is stored with each
+// signature, so bumping it makes `dedupe backfill` sign reviews again.
+const Version = 1
+
+// MinWords is the number of words a text needs to be signed. Shorter texts
+// such as "Good" are too common to tell guests apart.
+const MinWords = 3
+
+// shingleSize is the length in runes of the shingles hashed into a
+// signature. Shorter shingles make reworded reviews look alike, longer ones
+// make a changed word move the hash further.
+const shingleSize = 4
+
+// Options tunes what counts as a near-duplicate
+type Options struct {
+	MaxDistance int // Differing SimHash bits, 0-7
+	DateSlack   int // Days between the review dates
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxDistance: 3,
+		DateSlack:   2,
+	}
+}
+
+func ValidateOptions(v *validator.Validator, opts Options) {
+	v.Check(opts.MaxDistance >= 0 && opts.MaxDistance <= 7, "max_distance", "must be between 0 and 7")
+	v.Check(opts.DateSlack >= 0 && opts.DateSlack <= 30, "date_slack", "must be between 0 and 30")
+}
+
+// anonymous reviewer names that say nothing about who wrote a review
+var anonymous = map[string]bool{
+	"anonymous": true, "anonym": true, "anonyme": true, "anonimo": true, "anónimo": true,
+	"guest": true, "gast": true, "traveller": true, "traveler": true, "reviewer": true,
+}
+
+// Sign returns the SimHash of the review text, lower cased and stripped
+// of punctuation. ok is false when the text has fewer than MinWords words.
+func Sign(positives, negatives, comments string) (signature uint64, ok bool) {
+	words := tokenize(strings.Join([]string{positives, negatives, comments}, " "))
+	if len(words) < MinWords {
+		return 0, false
+	}
+
+	text := []rune(strings.Join(words, " "))
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(text); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(text[i : i+shingleSize])))
+		feature := h.Sum64()
+
+		for bit := range weights {
+			if feature&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	for bit, weight := range weights {
+		if weight > 0 {
+			signature |= 1 << bit
+		}
+	}
+
+	return signature, true
+}
+
+// Distance is the number of bits two signatures differ in
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// ReviewerKey normalises a reviewer display name to its first word, so
+// "John D." and "john" match. Anonymous names return "", which matches
+// any reviewer.
+func ReviewerKey(name string) string {
+	words := tokenize(name)
+	if len(words) == 0 || anonymous[words[0]] {
+		return ""
+	}
+	return words[0]
+}
+
+// Item is a signed review of one hotel
+type Item struct {
+	ID        int64
+	Signature uint64
+	Reviewer  string    // ReviewerKey of the display name
+	Date      time.Time // Zero when unknown
+}
+
+// Cluster groups near-duplicate items and maps each item ID to the smallest
+// ID in its cluster. Items without duplicates map to themselves.
+func Cluster(items []Item, opts Options) map[int64]int64 {
+	clusters, _ := cluster(items, opts)
+	return clusters
+}
+
+// cluster is Cluster, also returning the number of item pairs it compared.
+// Candidates are found by splitting signatures into MaxDistance+1 bands:
+// two signatures within MaxDistance bits of each other agree on at least
+// one band, and the bands stay as wide as that allows, 16 bits by default,
+// so random signatures rarely share a bucket.
+func cluster(items []Item, opts Options) (map[int64]int64, int) {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(i int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	bands := min(max(opts.MaxDistance+1, 1), 64)
+	compared := 0
+
+	for band := 0; band < bands; band++ {
+		from, to := band*64/bands, (band+1)*64/bands
+		mask := ^uint64(0)
+		if to-from < 64 {
+			mask = 1<<(to-from) - 1
+		}
+
+		buckets := make(map[uint64][]int)
+		for i, item := range items {
+			key := item.Signature >> from & mask
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					a, b := bucket[x], bucket[y]
+					ra, rb := find(a), find(b)
+					if ra == rb {
+						continue
+					}
+					compared++
+					if duplicates(items[a], items[b], opts) {
+						parent[max(ra, rb)] = min(ra, rb)
+					}
+				}
+			}
+		}
+	}
+
+	// Roots hold the cluster's smallest ID
+	minID := make(map[int]int64)
+	for i, item := range items {
+		root := find(i)
+		if id, ok := minID[root]; !ok || item.ID < id {
+			minID[root] = item.ID
+		}
+	}
+
+	clusters := make(map[int64]int64, len(items))
+	for i, item := range items {
+		clusters[item.ID] = minID[find(i)]
+	}
+
+	return clusters, compared
+}
+
+func duplicates(a, b Item, opts Options) bool {
+	if Distance(a.Signature, b.Signature) > opts.MaxDistance {
+		return false
+	}
+	if a.Reviewer != "" && b.Reviewer != "" && a.Reviewer != b.Reviewer {
+		return false
+	}
+	if !a.Date.IsZero() && !b.Date.IsZero() {
+		days := a.Date.Sub(b.Date).Abs().Hours() / 24
+		if days > float64(opts.DateSlack) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize lower cases text and splits it into words of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
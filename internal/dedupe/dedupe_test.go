@@ -0,0 +1,242 @@
+package dedupe
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	base := "The room was clean and the staff were friendly, breakfast could be better"
+	sig, ok := Sign("", "", base)
+	if !ok {
+		t.Fatalf("Sign(%q) not ok", base)
+	}
+
+	tests := []struct {
+		name        string
+		positives   string
+		negatives   string
+		comments    string
+		ok          bool
+		maxDistance int // From the signature of base
+		minDistance int
+	}{
+		{"too short", "Good", "", "", false, 0, 0},
+		{"two words", "Very good", "", "", false, 0, 0},
+		{"same text", "", "", base, true, 0, 0},
+		{"case and punctuation", "", "", "THE ROOM WAS CLEAN AND THE STAFF WERE FRIENDLY... Breakfast could be better!", true, 0, 0},
+		{"split across fields", "The room was clean and the staff were friendly,", "breakfast could be better", "", true, 0, 0},
+		{"one word changed", "", "", "The room was clean and the staff were friendly, breakfast could be nicer", true, 16, 1},
+		{"different review", "", "", "Terrible location next to the motorway, we could not sleep at all", true, 64, 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Sign(tt.positives, tt.negatives, tt.comments)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			d := Distance(sig, got)
+			if d > tt.maxDistance || d < tt.minDistance {
+				t.Errorf("distance from base = %d, want %d-%d", d, tt.minDistance, tt.maxDistance)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0b1011, 0b1011, 0},
+		{0b1011, 0b0011, 1},
+		{0, ^uint64(0), 64},
+		{0xF0F0, 0x0F0F, 16},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestReviewerKey(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"John D.", "john"},
+		{"john", "john"},
+		{"  Marie-Claire  ", "marie"},
+		{"Anonymous", ""},
+		{"Gast", ""},
+		{"Guest User", ""},
+		{"", ""},
+		{"...", ""},
+	}
+
+	for _, tt := range tests {
+		if got := ReviewerKey(tt.name); got != tt.want {
+			t.Errorf("ReviewerKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	const sig = 0x0123456789ABCDEF
+
+	tests := []struct {
+		name  string
+		items []Item
+		opts  Options
+		want  map[int64]int64
+	}{
+		{
+			name:  "empty",
+			items: nil,
+			opts:  DefaultOptions(),
+			want:  map[int64]int64{},
+		},
+		{
+			name: "identical text, same reviewer and day",
+			items: []Item{
+				{ID: 7, Signature: sig, Reviewer: "john", Date: day},
+				{ID: 3, Signature: sig, Reviewer: "john", Date: day},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{7: 3, 3: 3},
+		},
+		{
+			name: "within max distance",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig ^ 0b111, Date: day},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name: "beyond max distance",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig ^ 0b1111, Date: day},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 2},
+		},
+		{
+			name: "different reviewers",
+			items: []Item{
+				{ID: 1, Signature: sig, Reviewer: "john", Date: day},
+				{ID: 2, Signature: sig, Reviewer: "maria", Date: day},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 2},
+		},
+		{
+			name: "anonymous reviewer matches anyone",
+			items: []Item{
+				{ID: 1, Signature: sig, Reviewer: "john", Date: day},
+				{ID: 2, Signature: sig, Reviewer: "", Date: day},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name: "dates within slack",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig, Date: day.AddDate(0, 0, 2)},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name: "dates beyond slack",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig, Date: day.AddDate(0, 0, 3)},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 2},
+		},
+		{
+			name: "unknown date matches any date",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig},
+			},
+			opts: DefaultOptions(),
+			want: map[int64]int64{1: 1, 2: 1},
+		},
+		{
+			name: "chained duplicates share the smallest id",
+			items: []Item{
+				{ID: 9, Signature: sig, Date: day},
+				{ID: 5, Signature: sig ^ 0b11, Date: day},
+				{ID: 4, Signature: sig ^ 0b1111, Date: day},
+			},
+			opts: Options{MaxDistance: 2, DateSlack: 2},
+			want: map[int64]int64{9: 4, 5: 4, 4: 4},
+		},
+		{
+			name: "zero distance only",
+			items: []Item{
+				{ID: 1, Signature: sig, Date: day},
+				{ID: 2, Signature: sig ^ 1, Date: day},
+				{ID: 3, Signature: sig, Date: day},
+			},
+			opts: Options{MaxDistance: 0, DateSlack: 0},
+			want: map[int64]int64{1: 1, 2: 2, 3: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Cluster(tt.items, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cluster() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterScales(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	opts := DefaultOptions()
+
+	const unique, planted = 4000, 200
+	items := make([]Item, 0, unique+planted)
+	for i := range unique {
+		items = append(items, Item{ID: int64(i + 1), Signature: rng.Uint64()})
+	}
+	// Each planted item differs from an earlier item in MaxDistance bits
+	for i := range planted {
+		signature := items[i].Signature
+		for _, bit := range rng.Perm(64)[:opts.MaxDistance] {
+			signature ^= 1 << bit
+		}
+		items = append(items, Item{ID: int64(unique + i + 1), Signature: signature})
+	}
+
+	clusters, compared := cluster(items, opts)
+
+	for i := range planted {
+		if got := clusters[int64(unique+i+1)]; got != int64(i+1) {
+			t.Errorf("planted item %d in cluster %d, want %d", unique+i+1, got, i+1)
+		}
+	}
+	// Comparing every pair would take about 9 million comparisons
+	if limit := 2 * len(items); compared > limit {
+		t.Errorf("compared %d pairs of %d items, want at most %d", compared, len(items), limit)
+	}
+}
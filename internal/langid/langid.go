@@ -0,0 +1,317 @@
+// Package langid identifies the language of review text offline. Texts in
+// a script used by one language, such as Hangul or Thai, are identified by
+// their script. Latin and Cyrillic texts are scored against the character
+// n-gram profiles of the languages written in that script, built from the
+// sample texts embedded in corpus/, with a naive Bayes model. Texts the
+// model can't tell apart with enough confidence get no language.
+package langid
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Version identifies the profiles and rules that detected a language. It
+// is stored with each detection, so bumping it makes `language backfill`
+// detect existing reviews again.
+const Version = 2
+
+// MinLetters is the number of letters a text needs before it is identified
+const MinLetters = 10
+
+// MinConfidence is the lowest confidence Detect returns. Texts in a
+// language without a profile tend to score below it, spread over the
+// languages closest to theirs.
+const MinConfidence = 0.75
+
+// minMargin is the lowest mean log probability per n-gram by which the best
+// profile must beat the runner up. Confidence alone grows with the length
+// of a text, so a long text in an unknown language could pass it on a
+// margin too thin to mean anything.
+const minMargin = 0.15
+
+const maxGram = 3
+
+// evidence scales the n-gram log probabilities down before they become
+// Confidence. N-grams of one text are far from independent, so counting
+// each in full would score every sentence 1; the weight of a text instead
+// grows with the square root of its n-gram count.
+const evidence = 0.5
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// Result is a detected language as an ISO 639-1 code, with the model's
+// confidence from 0 to 1
+type Result struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+type profile struct {
+	language string
+	script   *unicode.RangeTable
+	logProb  map[string]float64
+	unseen   float64 // Log probability of an n-gram missing from the sample
+}
+
+var profiles = mustLoadProfiles()
+
+func mustLoadProfiles() []profile {
+	files, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic(fmt.Sprintf("langid corpus: %v", err))
+	}
+
+	var loaded []profile
+	for _, file := range files {
+		b, err := corpus.ReadFile(path.Join("corpus", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("langid corpus: %v", err))
+		}
+
+		script, _ := dominantScript(string(b))
+		if script == nil {
+			panic(fmt.Sprintf("langid corpus: %s has no profiled script", file.Name()))
+		}
+
+		counts := make(map[string]int)
+		total := 0
+		for _, gram := range ngrams(string(b)) {
+			counts[gram]++
+			total++
+		}
+
+		// Add-one smoothing over the seen n-grams and one unseen bucket
+		denominator := math.Log(float64(total + len(counts) + 1))
+		p := profile{
+			language: strings.TrimSuffix(file.Name(), ".txt"),
+			script:   script,
+			logProb:  make(map[string]float64, len(counts)),
+			unseen:   -denominator,
+		}
+		for gram, n := range counts {
+			p.logProb[gram] = math.Log(float64(n+1)) - denominator
+		}
+		loaded = append(loaded, p)
+	}
+
+	return loaded
+}
+
+// Languages returns the codes Detect can return, in order
+func Languages() []string {
+	languages := []string{}
+	for _, p := range profiles {
+		languages = append(languages, p.language)
+	}
+	for _, s := range scripts {
+		if !slices.Contains(languages, s.language) {
+			languages = append(languages, s.language)
+		}
+	}
+	slices.Sort(languages)
+	return languages
+}
+
+// DetectReview identifies the language a review was written in. The
+// original comment is used when the provider translated the review, then
+// the comment, then the liked and disliked text.
+func DetectReview(originalComment, comments, positives, negatives string) (Result, bool) {
+	for _, text := range []string{originalComment, comments, positives + "\n" + negatives} {
+		if result, ok := Detect(text); ok {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// Detect identifies the language of text. ok is false when the text has
+// fewer than MinLetters letters, is mostly in a script the package doesn't
+// know, or doesn't match one profile clearly enough: below MinConfidence
+// or minMargin.
+func Detect(text string) (Result, bool) {
+	if result, ok := detectScript(text); ok {
+		return result, true
+	}
+
+	script, ok := dominantScript(text)
+	if !ok {
+		return Result{}, false
+	}
+
+	grams := ngrams(text)
+	if len(grams) == 0 {
+		return Result{}, false
+	}
+
+	var candidates []profile
+	for _, p := range profiles {
+		if p.script == script {
+			candidates = append(candidates, p)
+		}
+	}
+
+	scores := make([]float64, len(candidates))
+	for i, p := range candidates {
+		for _, gram := range grams {
+			if lp, ok := p.logProb[gram]; ok {
+				scores[i] += lp
+			} else {
+				scores[i] += p.unseen
+			}
+		}
+	}
+
+	best, second := 0, -1
+	for i := 1; i < len(scores); i++ {
+		switch {
+		case scores[i] > scores[best]:
+			best, second = i, best
+		case second < 0 || scores[i] > scores[second]:
+			second = i
+		}
+	}
+	if second >= 0 && (scores[best]-scores[second])/float64(len(grams)) < minMargin {
+		return Result{}, false
+	}
+
+	scale := math.Min(1, evidence/math.Sqrt(float64(len(grams))))
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp((score - scores[best]) * scale)
+	}
+
+	confidence := math.Round(1/sum*1000) / 1000
+	if confidence < MinConfidence {
+		return Result{}, false
+	}
+
+	return Result{Language: candidates[best].language, Confidence: confidence}, true
+}
+
+// profiledScripts are the scripts written by several languages, told apart
+// by their n-gram profiles
+var profiledScripts = []*unicode.RangeTable{unicode.Latin, unicode.Cyrillic}
+
+// dominantScript returns the profiled script most letters of text are in,
+// if it is more than half of them
+func dominantScript(text string) (*unicode.RangeTable, bool) {
+	counts := make([]int, len(profiledScripts))
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for i, script := range profiledScripts {
+			if unicode.Is(script, r) {
+				counts[i]++
+				break
+			}
+		}
+	}
+
+	for i, n := range counts {
+		if n*2 > letters {
+			return profiledScripts[i], true
+		}
+	}
+	return nil, false
+}
+
+// scripts identifies languages by their writing system, for scripts
+// written by one language. Han without kana is taken as Chinese.
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Han, "zh"},
+	{unicode.Thai, "th"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+}
+
+// detectScript identifies text whose letters are mostly in one of scripts. Confidence is the share of letters in that script.
+func detectScript(text string) (Result, bool) {
+	counts := make(map[string]int)
+	letters := 0
+	kana := 0
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				if s.language == "ja" {
+					kana++
+				}
+				break
+			}
+		}
+	}
+	if letters < MinLetters && counts["zh"]+kana+counts["ko"] < MinLetters/2 {
+		return Result{}, false
+	}
+
+	// Japanese mixes kanji with kana
+	if kana > 0 {
+		counts["ja"] += counts["zh"]
+		delete(counts, "zh")
+	}
+
+	best, most := "", 0
+	for language, n := range counts {
+		if n > most || (n == most && language < best) {
+			best, most = language, n
+		}
+	}
+	if most*2 <= letters {
+		return Result{}, false
+	}
+
+	return Result{
+		Language:   best,
+		Confidence: math.Round(float64(most)/float64(letters)*1000) / 1000,
+	}, true
+}
+
+// ngrams returns the 1 to 3 letter n-grams of each word of text, lower
+// cased and padded with a space on either side. Words are runs of letters.
+func ngrams(text string) []string {
+	var grams []string
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	letters := 0
+	for _, word := range words {
+		padded := []rune(" " + word + " ")
+		letters += len(padded) - 2
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				gram := string(padded[i : i+n])
+				if gram != " " {
+					grams = append(grams, gram)
+				}
+			}
+		}
+	}
+
+	if letters < MinLetters {
+		return nil
+	}
+	return grams
+}
@@ -0,0 +1,114 @@
+package langid
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string // Empty when no language should be detected
+	}{
+		{"english", "The room was spacious and clean, and the staff were very helpful.", "en"},
+		{"german", "Das Zimmer war sauber und das Frühstück war sehr lecker.", "de"},
+		{"french", "La chambre était propre et le personnel très accueillant.", "fr"},
+		{"spanish", "La habitación estaba limpia y el personal fue muy amable.", "es"},
+		{"italian", "La camera era pulita e il personale molto gentile.", "it"},
+		{"dutch", "De kamer was schoon en het personeel was erg vriendelijk.", "nl"},
+		{"portuguese", "O quarto estava limpo e os funcionários foram muito simpáticos.", "pt"},
+		{"polish", "Pokój był czysty, a obsługa bardzo miła i pomocna.", "pl"},
+		{"turkish", "Oda çok temizdi ve personel son derece yardımseverdi.", "tr"},
+		{"swedish", "Rummet var rent och personalen var mycket trevlig.", "sv"},
+		{"czech", "Pokoj byl čistý a personál byl velmi příjemný.", "cs"},
+		{"hungarian", "A szoba tiszta volt, a személyzet nagyon kedves.", "hu"},
+		{"russian", "Номер был чистым, а персонал очень вежливым и внимательным.", "ru"},
+		{"ukrainian", "Номер був чистим, а персонал дуже привітним і уважним.", "uk"},
+		{"bulgarian", "Стаята беше чиста, а персоналът беше много любезен.", "bg"},
+		{"korean", "방이 깨끗하고 직원들이 친절했어요.", "ko"},
+		{"japanese", "部屋はとても綺麗で、スタッフも親切でした。", "ja"},
+		{"chinese", "房间很干净，服务员非常热情，早餐也很好吃。", "zh"},
+		{"greek", "Το δωμάτιο ήταν καθαρό και το προσωπικό ευγενικό.", "el"},
+		{"too short", "Great!", ""},
+		{"no letters", "10/10 !!! 👍👍", ""},
+		{"empty", "", ""},
+		{"lithuanian", "Kambarys buvo švarus, o darbuotojai labai malonūs.", ""},
+		{"swahili", "Chumba kilikuwa safi na wafanyakazi walikuwa wakarimu sana.", ""},
+		{"estonian", "Tuba oli puhas ja personal väga sõbralik.", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := Detect(tt.text)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("Detect(%q) = %+v, want no language", tt.text, result)
+				}
+				return
+			}
+			if !ok || result.Language != tt.want {
+				t.Errorf("Detect(%q) = %+v, %v, want %q", tt.text, result, ok, tt.want)
+			}
+			if result.Confidence < MinConfidence || result.Confidence > 1 {
+				t.Errorf("Detect(%q) confidence = %v, want %v to 1", tt.text, result.Confidence, MinConfidence)
+			}
+		})
+	}
+}
+
+func TestDetectReview(t *testing.T) {
+	tests := []struct {
+		name                                            string
+		originalComment, comments, positives, negatives string
+		want                                            string
+	}{
+		{
+			name:            "original comment first",
+			originalComment: "Das Zimmer war sauber und das Personal sehr freundlich.",
+			comments:        "The room was clean and the staff very friendly.",
+			want:            "de",
+		},
+		{
+			name:     "comment without an original",
+			comments: "La chambre était propre et le personnel très accueillant.",
+			want:     "fr",
+		},
+		{
+			name:      "liked and disliked text",
+			comments:  "Ok",
+			positives: "La habitación estaba limpia",
+			negatives: "el desayuno era bastante caro",
+			want:      "es",
+		},
+		{
+			name:     "nothing to detect",
+			comments: "Ok",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := DetectReview(tt.originalComment, tt.comments, tt.positives, tt.negatives)
+			if got := result.Language; got != tt.want || ok != (tt.want != "") {
+				t.Errorf("DetectReview() = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	languages := Languages()
+	if !slices.IsSorted(languages) {
+		t.Errorf("Languages() = %v, want sorted", languages)
+	}
+	if len(slices.Compact(slices.Clone(languages))) != len(languages) {
+		t.Errorf("Languages() = %v, want no duplicates", languages)
+	}
+	for _, language := range []string{"en", "ru", "uk", "bg", "ja", "zh", "ko"} {
+		if !slices.Contains(languages, language) {
+			t.Errorf("Languages() = %v, want %q", languages, language)
+		}
+	}
+}
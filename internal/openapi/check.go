@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Route is an endpoint registered by the server
+type Route struct {
+	Method  string
+	Pattern string
+	Scope   string
+}
+
+var pathParamRX = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Check compares the document with the routes the server registers. It
+// reports routes missing from the document and the other way around,
+// mismatched scopes, undocumented path parameters and references to
+// undefined schemas.
+func Check(doc *Document, routes []Route) []error {
+	var errs []error
+
+	documented := map[string]OperationRef{}
+	operationIDs := map[string]bool{}
+	for _, op := range doc.Operations() {
+		documented[op.Method+" "+op.Path] = op
+
+		switch {
+		case op.OperationID == "":
+			errs = append(errs, fmt.Errorf("%s %s: missing operationId", op.Method, op.Path))
+		case operationIDs[op.OperationID]:
+			errs = append(errs, fmt.Errorf("%s %s: duplicate operationId %s", op.Method, op.Path, op.OperationID))
+		}
+		operationIDs[op.OperationID] = true
+
+		errs = append(errs, checkParameters(op)...)
+
+		where := op.Method + " " + op.Path
+		for _, resp := range op.Responses {
+			for _, media := range resp.Content {
+				errs = append(errs, checkRefs(doc, where, media.Schema)...)
+			}
+		}
+		for _, param := range op.Parameters {
+			errs = append(errs, checkRefs(doc, where, param.Schema)...)
+		}
+	}
+
+	for name, schema := range doc.Components.Schemas {
+		errs = append(errs, checkRefs(doc, "schema "+name, schema)...)
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		key := route.Method + " " + route.Pattern
+		registered[key] = true
+
+		op, ok := documented[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: route is not documented", key))
+			continue
+		}
+
+		if op.Scope != route.Scope {
+			errs = append(errs, fmt.Errorf("%s: documented scope %q, server requires %q", key, op.Scope, route.Scope))
+		}
+
+		if secured := len(op.Security) > 0; secured != (route.Scope != "") {
+			errs = append(errs, fmt.Errorf("%s: security does not match the required scope %q", key, route.Scope))
+		}
+	}
+
+	for _, op := range doc.Operations() {
+		if key := op.Method + " " + op.Path; !registered[key] {
+			errs = append(errs, fmt.Errorf("%s: documented but not served", key))
+		}
+	}
+
+	return errs
+}
+
+// checkParameters makes sure every path parameter is documented and every
+// documented path parameter is in the path
+func checkParameters(op OperationRef) []error {
+	var errs []error
+
+	inPath := []string{}
+	for _, match := range pathParamRX.FindAllStringSubmatch(op.Path, -1) {
+		inPath = append(inPath, match[1])
+	}
+
+	for _, param := range op.Parameters {
+		if param.In == "path" && !slices.Contains(inPath, param.Name) {
+			errs = append(errs, fmt.Errorf("%s %s: path parameter %s is not in the path", op.Method, op.Path, param.Name))
+		}
+	}
+
+	for _, name := range inPath {
+		found := slices.ContainsFunc(op.Parameters, func(p *Parameter) bool {
+			return p.In == "path" && p.Name == name
+		})
+		if !found {
+			errs = append(errs, fmt.Errorf("%s %s: path parameter %s is not documented", op.Method, op.Path, name))
+		}
+	}
+
+	return errs
+}
+
+// checkRefs reports schema references within schema that don't resolve
+func checkRefs(doc *Document, where string, schema *Schema) []error {
+	var errs []error
+
+	var walk func(s *Schema)
+	walk = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			name := refName(s.Ref, "schemas")
+			if _, ok := doc.Components.Schemas[name]; !ok || strings.Contains(name, "/") {
+				errs = append(errs, fmt.Errorf("%s: unknown schema %s", where, s.Ref))
+			}
+			return
+		}
+		for _, prop := range s.Properties {
+			walk(prop)
+		}
+		walk(s.Items)
+		walk(s.AdditionalProperties)
+	}
+	walk(schema)
+
+	return errs
+}
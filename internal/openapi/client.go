@@ -0,0 +1,425 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// initialisms are kept upper case in generated Go names
+var initialisms = map[string]string{
+	"api":  "API",
+	"id":   "ID",
+	"json": "JSON",
+	"s3":   "S3",
+	"url":  "URL",
+}
+
+// GenerateClient writes the types and methods of the Go client for doc as
+// package pkg. The output relies on the Client type and its do method,
+// which are written by hand in the same package.
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &generator{doc: doc, types: map[string]*Schema{}, imports: map[string]bool{}}
+
+	ops := doc.Operations()
+	for _, op := range ops {
+		if _, schema := op.SuccessResponse(); schema != nil {
+			g.collect(schema)
+		}
+	}
+
+	names := make([]string, 0, len(g.types))
+	for name := range g.types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if err := g.writeStruct(name, g.types[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, op := range ops {
+		if err := g.writeOperation(op); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by go run ./cmd/openapi client. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	fmt.Fprintf(&out, "import (\n")
+	for _, imp := range sortedKeys(g.imports) {
+		fmt.Fprintf(&out, "%q\n", imp)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated client: %w", err)
+	}
+
+	return src, nil
+}
+
+type generator struct {
+	doc     *Document
+	buf     bytes.Buffer
+	types   map[string]*Schema // Struct types to generate, by Go name
+	imports map[string]bool
+}
+
+// collect registers the component schemas reachable from schema
+func (g *generator) collect(schema *Schema) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Ref != "" {
+		name := refName(schema.Ref, "schemas")
+		if _, seen := g.types[name]; seen {
+			return
+		}
+		resolved := g.doc.Components.Schemas[name]
+		g.types[name] = resolved
+		g.collect(resolved)
+		return
+	}
+
+	for _, prop := range schema.Properties {
+		g.collect(prop)
+	}
+	g.collect(schema.Items)
+	g.collect(schema.AdditionalProperties)
+}
+
+func (g *generator) writeStruct(name string, schema *Schema) error {
+	if schema == nil || schema.Type != "object" || len(schema.Properties) == 0 {
+		return fmt.Errorf("schema %s: only objects with properties can be generated", name)
+	}
+
+	nested := map[string]*Schema{}
+
+	writeComment(&g.buf, schema.Description)
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+
+	for _, prop := range sortedKeys(schema.Properties) {
+		propSchema := schema.Properties[prop]
+
+		typ, err := g.goType(propSchema)
+		if err != nil {
+			return fmt.Errorf("schema %s property %s: %w", name, prop, err)
+		}
+
+		// Inline objects become their own named type
+		if propSchema.Ref == "" && propSchema.Type == "object" && len(propSchema.Properties) > 0 {
+			typ = name + goName(prop)
+			nested[typ] = propSchema
+		}
+
+		tag := prop
+		if !slices.Contains(schema.Required, prop) {
+			tag += ",omitempty"
+		}
+
+		writeComment(&g.buf, propSchema.Description)
+		fmt.Fprintf(&g.buf, "%s %s `json:\"%s\"`\n", goName(prop), typ, tag)
+	}
+
+	fmt.Fprintf(&g.buf, "}\n\n")
+
+	for _, nestedName := range sortedKeys(nested) {
+		if err := g.writeStruct(nestedName, nested[nestedName]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// goType returns the Go type of a schema. Nullable scalars are pointers.
+func (g *generator) goType(schema *Schema) (string, error) {
+	if schema.Ref != "" {
+		return refName(schema.Ref, "schemas"), nil
+	}
+
+	var typ string
+	switch schema.Type {
+	case "string":
+		typ = "string"
+		if schema.Format == "date-time" {
+			typ = "time.Time"
+			g.imports["time"] = true
+		}
+	case "integer":
+		typ = "int"
+		if schema.Format == "int64" {
+			typ = "int64"
+		}
+	case "number":
+		typ = "float64"
+	case "boolean":
+		typ = "bool"
+	case "array":
+		if schema.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		items, err := g.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case "object":
+		if schema.AdditionalProperties != nil {
+			values, err := g.goType(schema.AdditionalProperties)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + values, nil
+		}
+		if len(schema.Properties) == 0 {
+			g.imports["encoding/json"] = true
+			return "json.RawMessage", nil
+		}
+		return "", nil // Named by writeStruct
+	case "":
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	default:
+		return "", fmt.Errorf("unsupported type %q", schema.Type)
+	}
+
+	if schema.Nullable {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// writeOperation writes a Params struct for the optional query parameters
+// and the client method. Path parameters and required query parameters are
+// method arguments.
+func (g *generator) writeOperation(op OperationRef) error {
+	method := goName(op.OperationID)
+
+	args := []string{"ctx context.Context"}
+	var path, required, optional []*Parameter
+
+	for _, param := range op.Parameters {
+		switch {
+		case param.In == "path":
+			path = append(path, param)
+		case param.In == "query" && param.Required:
+			required = append(required, param)
+		case param.In == "query":
+			optional = append(optional, param)
+		default:
+			return fmt.Errorf("%s: unsupported parameter location %q", op.OperationID, param.In)
+		}
+	}
+
+	for _, param := range append(slices.Clone(path), required...) {
+		typ, err := g.goType(param.Schema)
+		if err != nil {
+			return fmt.Errorf("%s parameter %s: %w", op.OperationID, param.Name, err)
+		}
+		args = append(args, lowerFirst(goName(param.Name))+" "+typ)
+	}
+
+	paramsType := method + "Params"
+	if len(optional) > 0 {
+		fmt.Fprintf(&g.buf, "// %s holds the optional query parameters of %s. Nil fields are not sent.\n", paramsType, method)
+		fmt.Fprintf(&g.buf, "type %s struct {\n", paramsType)
+		for _, param := range optional {
+			typ, err := g.goType(param.Schema)
+			if err != nil {
+				return fmt.Errorf("%s parameter %s: %w", op.OperationID, param.Name, err)
+			}
+			writeComment(&g.buf, param.Description)
+			fmt.Fprintf(&g.buf, "%s *%s\n", goName(param.Name), strings.TrimPrefix(typ, "*"))
+		}
+		fmt.Fprintf(&g.buf, "}\n\n")
+		args = append(args, "params *"+paramsType)
+	}
+
+	_, schema := op.SuccessResponse()
+	result := "json.RawMessage"
+	if schema != nil && schema.Ref != "" {
+		result = "*" + refName(schema.Ref, "schemas")
+	}
+
+	comment := fmt.Sprintf("%s calls %s %s.", method, op.Method, op.Path)
+	if op.Summary != "" {
+		comment += " " + strings.TrimSuffix(op.Summary, ".") + "."
+	}
+	if op.Scope != "" {
+		comment += fmt.Sprintf(" Requires the %s scope.", op.Scope)
+	}
+	writeComment(&g.buf, comment)
+	fmt.Fprintf(&g.buf, "func (c *Client) %s(%s) (%s, error) {\n", method, strings.Join(args, ", "), result)
+
+	pathExpr, err := g.pathExpr(op.Path, path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op.OperationID, err)
+	}
+
+	g.imports["context"] = true
+	g.imports["net/http"] = true
+	g.imports["net/url"] = true
+
+	fmt.Fprintf(&g.buf, "query := url.Values{}\n")
+	for _, param := range required {
+		value, err := g.formatValue(param.Schema, lowerFirst(goName(param.Name)))
+		if err != nil {
+			return fmt.Errorf("%s parameter %s: %w", op.OperationID, param.Name, err)
+		}
+		fmt.Fprintf(&g.buf, "query.Set(%q, %s)\n", param.Name, value)
+	}
+	if len(optional) > 0 {
+		fmt.Fprintf(&g.buf, "if params != nil {\n")
+		for _, param := range optional {
+			field := "params." + goName(param.Name)
+			value, err := g.formatValue(param.Schema, "*"+field)
+			if err != nil {
+				return fmt.Errorf("%s parameter %s: %w", op.OperationID, param.Name, err)
+			}
+			fmt.Fprintf(&g.buf, "if %s != nil {\nquery.Set(%q, %s)\n}\n", field, param.Name, value)
+		}
+		fmt.Fprintf(&g.buf, "}\n")
+	}
+
+	if result == "json.RawMessage" {
+		g.imports["encoding/json"] = true
+		fmt.Fprintf(&g.buf, "var out json.RawMessage\n")
+		fmt.Fprintf(&g.buf, "err := c.do(ctx, http.Method%s, %s, query, &out)\n", httpMethodConst(op.Method), pathExpr)
+	} else {
+		fmt.Fprintf(&g.buf, "out := new(%s)\n", strings.TrimPrefix(result, "*"))
+		fmt.Fprintf(&g.buf, "err := c.do(ctx, http.Method%s, %s, query, out)\n", httpMethodConst(op.Method), pathExpr)
+	}
+	fmt.Fprintf(&g.buf, "if err != nil {\nreturn nil, err\n}\nreturn out, nil\n}\n\n")
+
+	return nil
+}
+
+// pathExpr returns the expression that builds path with its parameters
+// filled in
+func (g *generator) pathExpr(path string, params []*Parameter) (string, error) {
+	// Fill the parameters in the order they appear in the path
+	params = slices.Clone(params)
+	slices.SortFunc(params, func(a, b *Parameter) int {
+		return strings.Index(path, "{"+a.Name+"}") - strings.Index(path, "{"+b.Name+"}")
+	})
+
+	var parts []string
+	rest := path
+	for _, param := range params {
+		before, after, ok := strings.Cut(rest, "{"+param.Name+"}")
+		if !ok {
+			return "", fmt.Errorf("path parameter %s is not in %s", param.Name, path)
+		}
+
+		value, err := g.formatValue(param.Schema, lowerFirst(goName(param.Name)))
+		if err != nil {
+			return "", fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+
+		if before != "" {
+			parts = append(parts, strconv.Quote(before))
+		}
+		parts = append(parts, "url.PathEscape("+value+")")
+		rest = after
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(rest))
+	}
+
+	return strings.Join(parts, " + "), nil
+}
+
+// formatValue returns the expression that formats v for a URL
+func (g *generator) formatValue(schema *Schema, v string) (string, error) {
+	if schema.Type != "string" {
+		g.imports["strconv"] = true
+	}
+
+	switch schema.Type {
+	case "string":
+		return v, nil
+	case "integer":
+		if schema.Format == "int64" {
+			return fmt.Sprintf("strconv.FormatInt(%s, 10)", v), nil
+		}
+		return fmt.Sprintf("strconv.Itoa(%s)", v), nil
+	case "number":
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'f', -1, 64)", v), nil
+	case "boolean":
+		return fmt.Sprintf("strconv.FormatBool(%s)", v), nil
+	default:
+		return "", fmt.Errorf("unsupported parameter type %q", schema.Type)
+	}
+}
+
+func httpMethodConst(method string) string {
+	return method[:1] + strings.ToLower(method[1:])
+}
+
+// goName converts snake_case and camelCase names to exported Go names
+func goName(name string) string {
+	var words []string
+	for _, part := range strings.Split(name, "_") {
+		start := 0
+		for i := 1; i < len(part); i++ {
+			if part[i] >= 'A' && part[i] <= 'Z' && part[i-1] >= 'a' && part[i-1] <= 'z' {
+				words = append(words, part[start:i])
+				start = i
+			}
+		}
+		words = append(words, part[start:])
+	}
+
+	var sb strings.Builder
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		if initialism, ok := initialisms[strings.ToLower(word)]; ok {
+			sb.WriteString(initialism)
+			continue
+		}
+		sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return sb.String()
+}
+
+func lowerFirst(name string) string {
+	for initialism := range initialisms {
+		upper := initialisms[initialism]
+		if name == upper {
+			return initialism
+		}
+		if strings.HasPrefix(name, upper) && len(name) > len(upper) && name[len(upper)] >= 'A' && name[len(upper)] <= 'Z' {
+			return initialism + name[len(upper):]
+		}
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func writeComment(buf *bytes.Buffer, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(buf, "// %s\n", text)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,164 @@
+// Package openapi reads the subset of OpenAPI 3.0 used by the review API
+// document, checks the document against the server's routes and generates
+// the Go client from it.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lower case HTTP methods to operations. Path level
+// parameters are not supported.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Parameters  []*Parameter          `json:"parameters"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+	Scope       string                `json:"x-scope"` // API key scope the server requires
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref"`
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Nullable             bool               `json:"nullable"`
+	Description          string             `json:"description"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Enum                 []any              `json:"enum"`
+}
+
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas"`
+	Parameters map[string]*Parameter `json:"parameters"`
+	Responses  map[string]*Response  `json:"responses"`
+}
+
+// OperationRef is an operation with the method and path it is served at
+type OperationRef struct {
+	Method string // Upper case
+	Path   string
+	*Operation
+}
+
+// Load parses an OpenAPI document and resolves its parameter and response
+// references, so callers only see schema references
+func Load(b []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parsing openapi document: %w", err)
+	}
+
+	for _, item := range doc.Paths {
+		for _, op := range item {
+			for i, param := range op.Parameters {
+				if param.Ref == "" {
+					continue
+				}
+				resolved, ok := doc.Components.Parameters[refName(param.Ref, "parameters")]
+				if !ok {
+					return nil, fmt.Errorf("%s: unknown parameter %s", op.OperationID, param.Ref)
+				}
+				op.Parameters[i] = resolved
+			}
+
+			for status, resp := range op.Responses {
+				if resp.Ref == "" {
+					continue
+				}
+				resolved, ok := doc.Components.Responses[refName(resp.Ref, "responses")]
+				if !ok {
+					return nil, fmt.Errorf("%s: unknown response %s", op.OperationID, resp.Ref)
+				}
+				op.Responses[status] = resolved
+			}
+		}
+	}
+
+	return &doc, nil
+}
+
+// Operations returns every operation sorted by path and method
+func (d *Document) Operations() []OperationRef {
+	ops := []OperationRef{}
+	for path, item := range d.Paths {
+		for method, op := range item {
+			ops = append(ops, OperationRef{Method: strings.ToUpper(method), Path: path, Operation: op})
+		}
+	}
+
+	slices.SortFunc(ops, func(a, b OperationRef) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	return ops
+}
+
+// SuccessResponse returns the status and schema of the operation's 2xx
+// response. The schema is nil when the response has no JSON body.
+func (op *Operation) SuccessResponse() (string, *Schema) {
+	statuses := []string{}
+	for status := range op.Responses {
+		if strings.HasPrefix(status, "2") {
+			statuses = append(statuses, status)
+		}
+	}
+	if len(statuses) == 0 {
+		return "", nil
+	}
+	slices.Sort(statuses)
+
+	media, ok := op.Responses[statuses[0]].Content["application/json"]
+	if !ok {
+		return statuses[0], nil
+	}
+	return statuses[0], media.Schema
+}
+
+// refName returns the component name of a local reference such as
+// #/components/schemas/Hotel
+func refName(ref, kind string) string {
+	return strings.TrimPrefix(ref, "#/components/"+kind+"/")
+}
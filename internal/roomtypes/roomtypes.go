@@ -0,0 +1,139 @@
+// Package roomtypes maps the free-text room type names providers send, such
+// as "Deluxe Double Room" or "Double Room - Deluxe", onto a canonical
+// category like "Deluxe Double".
+package roomtypes
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Fallback is the room type of a name that matches no type rule
+const Fallback = "Room"
+
+//go:embed rules.json
+var defaultRules []byte
+
+// Rules map room type names to categories. The file lists type rules, e.g.
+// Double or Suite, and tier rules, e.g. Deluxe, each with the terms that
+// select it:
+//
+//	{"types": [{"category": "Double", "terms": ["double", "king"]}],
+//	 "tiers": [{"category": "Deluxe", "terms": ["deluxe"]}]}
+//
+// Terms match whole words, case insensitively, and may be phrases. The
+// first matching rule of each list wins, so more specific rules go first.
+type Rules struct {
+	// Version identifies the rules. Room types store the version that
+	// categorized them so `room-types backfill` can recategorize after a
+	// change.
+	Version string
+
+	types []rule
+	tiers []rule
+}
+
+type rule struct {
+	Category string   `json:"category"`
+	Terms    []string `json:"terms"`
+}
+
+// Default returns the rules embedded in the binary
+var Default = sync.OnceValue(func() *Rules {
+	r, err := Parse(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("embedded room type rules: %v", err))
+	}
+	return r
+})
+
+// Parse parses a rules file
+func Parse(b []byte) (*Rules, error) {
+	var file struct {
+		Types []rule `json:"types"`
+		Tiers []rule `json:"tiers"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Types) == 0 {
+		return nil, fmt.Errorf("rules have no room types")
+	}
+
+	sum := sha256.Sum256(b)
+	r := &Rules{Version: hex.EncodeToString(sum[:6])}
+
+	for _, list := range []struct {
+		rules  []rule
+		target *[]rule
+	}{
+		{file.Types, &r.types},
+		{file.Tiers, &r.tiers},
+	} {
+		for _, rl := range list.rules {
+			if rl.Category == "" {
+				return nil, fmt.Errorf("category must not be empty")
+			}
+
+			normalized := rule{Category: rl.Category}
+			for _, term := range rl.Terms {
+				words := normalize(term)
+				if words == "" {
+					return nil, fmt.Errorf("%s: term %q has no words", rl.Category, term)
+				}
+				normalized.Terms = append(normalized.Terms, words)
+			}
+			*list.target = append(*list.target, normalized)
+		}
+	}
+
+	return r, nil
+}
+
+// Categorize returns the canonical category of a room type name: the tier,
+// if any, followed by the type, e.g. "Deluxe Double" or "Suite". Names that
+// match no type are Fallback, with their tier. Empty names return "".
+func (r *Rules) Categorize(name string) string {
+	words := normalize(name)
+	if words == "" {
+		return ""
+	}
+	padded := " " + words + " "
+
+	category := match(r.types, padded)
+	if category == "" {
+		category = Fallback
+	}
+	if tier := match(r.tiers, padded); tier != "" {
+		category = tier + " " + category
+	}
+
+	return category
+}
+
+// match returns the category of the first rule with a term in padded
+func match(rules []rule, padded string) string {
+	for _, rl := range rules {
+		for _, term := range rl.Terms {
+			if strings.Contains(padded, " "+term+" ") {
+				return rl.Category
+			}
+		}
+	}
+	return ""
+}
+
+// normalize lower cases text and joins its words, runs of letters and
+// digits, with single spaces
+func normalize(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,94 @@
+package roomtypes
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{"valid", `{"types": [{"category": "Double", "terms": ["double"]}]}`, false},
+		{"no tiers", `{"types": [{"category": "Double", "terms": ["double"]}], "tiers": []}`, false},
+		{"not json", `{"types": [`, true},
+		{"no types", `{"tiers": [{"category": "Deluxe", "terms": ["deluxe"]}]}`, true},
+		{"empty category", `{"types": [{"category": "", "terms": ["double"]}]}`, true},
+		{"term without words", `{"types": [{"category": "Double", "terms": [" - "]}]}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCategorize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"", ""},
+		{" - ", ""},
+		{"Double Room", "Double"},
+		{"Deluxe Double Room", "Deluxe Double"},
+		{"Double Room - Deluxe", "Deluxe Double"},
+		{"DELUXE   KING", "Deluxe Double"},
+		{"Standard Twin Room", "Twin"},
+		{"Room with 2 single beds", "Twin"},
+		{"Superior Junior Suite", "Superior Junior Suite"},
+		{"Presidential Suite", "Suite"},
+		{"Family Room with Balcony", "Family"},
+		{"Bed in 6-Bed Mixed Dormitory", "Dormitory"},
+		{"Executive Room", "Executive Room"},
+		{"Standard Room", Fallback},
+		{"Doppelzimmer", "Double"},
+		{"Habitación Doble", "Double"},
+		{"Doubles", Fallback},
+		{"Kingfisher Room", Fallback},
+	}
+
+	rules := Default()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Categorize(tt.name); got != tt.want {
+				t.Errorf("Categorize(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategorizeRuleOrder(t *testing.T) {
+	rules, err := Parse([]byte(`{
+		"types": [
+			{"category": "Junior Suite", "terms": ["junior suite"]},
+			{"category": "Suite", "terms": ["suite"]}
+		],
+		"tiers": [
+			{"category": "Premium", "terms": ["premium"]},
+			{"category": "Deluxe", "terms": ["deluxe"]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Junior Suite", "Junior Suite"},
+		{"Suite, junior", "Suite"},
+		{"Deluxe Premium Suite", "Premium Suite"},
+		{"Deluxe", "Deluxe " + Fallback},
+	}
+
+	for _, tt := range tests {
+		if got := rules.Categorize(tt.name); got != tt.want {
+			t.Errorf("Categorize(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -9,9 +9,28 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// Config describes how to reach S3 or an S3-compatible store such as MinIO
+// or LocalStack. Only Region is required; credentials fall back to the
+// default AWS chain when no static keys or profile are given.
+type Config struct {
+	Region          string
+	Endpoint        string // Custom endpoint URL, e.g. http://localhost:9000
+	UsePathStyle    bool   // Address buckets as endpoint/bucket instead of bucket.endpoint
+	AccessKey       string
+	SecretKey       string
+	SessionToken    string
+	Profile         string // Named profile from the shared AWS config files
+	RoleARN         string // Role to assume on top of the base credentials
+	ExternalID      string
+	RoleSessionName string
+}
+
 type Client struct {
 	s3Client *s3.Client
 }
@@ -31,15 +50,50 @@ type S3FileReader struct {
 	client *Client
 }
 
-func NewClient(s3Region string) (*Client, error) {
-	awsCfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(s3Region))
+func NewClient(cfg Config) (*Client, error) {
+	if (cfg.AccessKey == "") != (cfg.SecretKey == "") {
+		return nil, fmt.Errorf("both access key and secret key must be set for static credentials")
+	}
+
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(cfg.Region),
+	}
+
+	if cfg.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(cfg.Profile))
+	}
+
+	if cfg.AccessKey != "" {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKey, cfg.SecretKey, cfg.SessionToken),
+		))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), opts...)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	if cfg.RoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if cfg.ExternalID != "" {
+				o.ExternalID = aws.String(cfg.ExternalID)
+			}
+			if cfg.RoleSessionName != "" {
+				o.RoleSessionName = cfg.RoleSessionName
+			}
+		})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
 	return &Client{
-		s3Client: s3.NewFromConfig(awsCfg),
+		s3Client: s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+			if cfg.Endpoint != "" {
+				o.BaseEndpoint = aws.String(cfg.Endpoint)
+			}
+			o.UsePathStyle = cfg.UsePathStyle
+		}),
 	}, nil
 }
 
@@ -52,21 +106,24 @@ func (c *Client) ListFiles(ctx context.Context, bucket, prefix string) ([]FileIn
 		input.Prefix = aws.String(prefix)
 	}
 
-	result, err := c.s3Client.ListObjectsV2(ctx, input)
-
-	if err != nil {
-		return nil, fmt.Errorf("faild to list objects in bucket %s: %w", bucket, err)
-	}
-
-	files := make([]FileInfo, 0, len(result.Contents))
-
-	for _, obj := range result.Contents {
-		files = append(files, FileInfo{
-			Key:          *obj.Key,
-			Size:         *obj.Size,
-			S3Path:       fmt.Sprintf("s3://%s/%s", bucket, *obj.Key),
-			LastModified: *obj.LastModified,
-		})
+	files := make([]FileInfo, 0)
+
+	// A bucket or prefix can hold more than one page of objects
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, input)
+	for paginator.HasMorePages() {
+		result, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("faild to list objects in bucket %s: %w", bucket, err)
+		}
+
+		for _, obj := range result.Contents {
+			files = append(files, FileInfo{
+				Key:          *obj.Key,
+				Size:         *obj.Size,
+				S3Path:       fmt.Sprintf("s3://%s/%s", bucket, *obj.Key),
+				LastModified: *obj.LastModified,
+			})
+		}
 	}
 
 	return files, nil
@@ -77,16 +134,10 @@ func NewS3FileReader(client *Client) FileReader {
 }
 
 func (s *S3FileReader) GetReader(ctx context.Context, s3Path string) (io.ReadCloser, error) {
-	if !strings.HasPrefix(s3Path, "s3://") {
-		return nil, fmt.Errorf("invalid s3 path (missing s3:// prefix): %s", s3Path)
-	}
-
-	trimmed := strings.TrimPrefix(s3Path, "s3://")
-	parts := strings.SplitN(trimmed, "/", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid s3 path format: %s", s3Path)
+	bucket, key, err := ParsePath(s3Path)
+	if err != nil {
+		return nil, err
 	}
-	bucket, key := parts[0], parts[1]
 
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
@@ -100,3 +151,18 @@ func (s *S3FileReader) GetReader(ctx context.Context, s3Path string) (io.ReadClo
 
 	return result.Body, nil
 }
+
+// ParsePath splits an s3://bucket/key path into its bucket and key
+func ParsePath(s3Path string) (bucket, key string, err error) {
+	if !strings.HasPrefix(s3Path, "s3://") {
+		return "", "", fmt.Errorf("invalid s3 path (missing s3:// prefix): %s", s3Path)
+	}
+
+	trimmed := strings.TrimPrefix(s3Path, "s3://")
+	parts := strings.SplitN(trimmed, "/", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid s3 path format: %s", s3Path)
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,318 @@
+// Package sentiment scores review text with an embedded word lexicon and
+// the rules of VADER (Hutto and Gilbert, 2014): words are adjusted for
+// intensifiers, negation, capitals, "but" and exclamation marks before
+// their valences are summed and normalised. It needs no network access or
+// model files, and only knows English.
+package sentiment
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version identifies the lexicon and rules that produced a score. It is
+// stored with each score, so bumping it makes `sentiment backfill` rescore
+// existing reviews.
+const Version = 2
+
+//go:embed lexicon.txt
+var lexiconFile string
+
+var lexicon = mustParseLexicon(lexiconFile)
+
+// Scores of a text. Compound is the normalised overall valence from -1
+// (most negative) to 1 (most positive); Positive, Negative and Neutral are
+// the shares of the text in each class and add up to 1.
+type Scores struct {
+	Compound float64 `json:"compound"`
+	Positive float64 `json:"positive"`
+	Negative float64 `json:"negative"`
+	Neutral  float64 `json:"neutral"`
+}
+
+// Rule constants from the VADER paper
+const (
+	boosterIncrement = 0.293
+	capsIncrement    = 0.733
+	negationScalar   = -0.74
+	exclamationBoost = 0.292
+	normalizeAlpha   = 15
+)
+
+// boosters raise or lower the intensity of the word that follows them
+var boosters = map[string]float64{
+	"absolutely": boosterIncrement, "amazingly": boosterIncrement, "completely": boosterIncrement,
+	"extremely": boosterIncrement, "exceptionally": boosterIncrement, "especially": boosterIncrement,
+	"highly": boosterIncrement, "incredibly": boosterIncrement, "really": boosterIncrement,
+	"so": boosterIncrement, "super": boosterIncrement, "terribly": boosterIncrement,
+	"too": boosterIncrement, "totally": boosterIncrement, "truly": boosterIncrement,
+	"utterly": boosterIncrement, "very": boosterIncrement, "most": boosterIncrement,
+	"more": boosterIncrement, "particularly": boosterIncrement, "quite": boosterIncrement,
+	"almost": -boosterIncrement, "barely": -boosterIncrement, "hardly": -boosterIncrement,
+	"slightly": -boosterIncrement, "somewhat": -boosterIncrement, "little": -boosterIncrement,
+	"marginally": -boosterIncrement, "partly": -boosterIncrement, "fairly": -boosterIncrement,
+	"bit": -boosterIncrement, "less": -boosterIncrement,
+}
+
+// negations flip and dampen the valence of the words up to three after them
+var negations = map[string]bool{
+	"not": true, "no": true, "never": true, "none": true, "nobody": true, "nothing": true,
+	"neither": true, "nor": true, "nowhere": true, "cannot": true, "without": true,
+	"isnt": true, "wasnt": true, "arent": true, "werent": true, "dont": true, "doesnt": true,
+	"didnt": true, "cant": true, "couldnt": true, "wouldnt": true, "shouldnt": true,
+	"wont": true, "hasnt": true, "havent": true, "hadnt": true, "aint": true,
+	"rarely": true, "seldom": true, "despite": true,
+}
+
+func mustParseLexicon(file string) map[string]float64 {
+	words := make(map[string]float64)
+
+	scanner := bufio.NewScanner(strings.NewReader(file))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		word, valence, ok := strings.Cut(text, "\t")
+		if !ok {
+			panic(fmt.Sprintf("sentiment lexicon line %d: expected word<TAB>valence", line))
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(valence), 64)
+		if err != nil {
+			panic(fmt.Sprintf("sentiment lexicon line %d: %v", line, err))
+		}
+		words[word] = v
+	}
+
+	return words
+}
+
+// ScoreReview scores the free text of a review. Each field is scored as
+// separate sentences, so a "but" in one doesn't reweigh the others.
+func ScoreReview(positives, negatives, comments string) Scores {
+	return Score(strings.Join([]string{positives, negatives, comments}, "\n"))
+}
+
+// Score scores text. Long texts are split into sentences and Compound is
+// the mean of the sentences that carry any sentiment, so a long review is
+// not pushed to ±1 just by its length. Text without lexicon words scores
+// 0 and fully Neutral.
+func Score(text string) Scores {
+	var compoundSum float64
+	var scored int
+	var pos, neg, neu float64
+
+	for _, sentence := range splitSentences(text) {
+		s := scoreSentence(sentence)
+		if s.valences == 0 {
+			neu += s.neutral
+			continue
+		}
+
+		compoundSum += s.compound
+		scored++
+		pos += s.positive
+		neg += s.negative
+		neu += s.neutral
+	}
+
+	scores := Scores{Neutral: 1}
+	if scored > 0 {
+		scores.Compound = round(compoundSum / float64(scored))
+	}
+	if total := pos + neg + neu; total > 0 && pos+neg > 0 {
+		scores.Positive = round(pos / total)
+		scores.Negative = round(neg / total)
+		scores.Neutral = round(neu / total)
+	}
+
+	return scores
+}
+
+type sentenceScores struct {
+	compound                    float64
+	positive, negative, neutral float64 // Sums, not yet shares
+	valences                    int     // Number of lexicon words found
+}
+
+func scoreSentence(sentence string) sentenceScores {
+	words := tokenize(sentence)
+	if len(words) == 0 {
+		return sentenceScores{}
+	}
+
+	lower := make([]string, len(words))
+	var upper, mixed bool
+	for i, word := range words {
+		lower[i] = strings.ToLower(word)
+		if isUpper(word) {
+			upper = true
+		} else {
+			mixed = true
+		}
+	}
+	// Capitals only emphasise when the rest of the text isn't shouting too
+	capsDiff := upper && mixed
+
+	var result sentenceScores
+	valences := make([]float64, len(words))
+
+	for i, word := range lower {
+		if _, ok := boosters[word]; ok {
+			continue
+		}
+		// "kind of" dampens rather than being kind
+		if word == "kind" && i+1 < len(lower) && lower[i+1] == "of" {
+			continue
+		}
+
+		v, ok := lexicon[word]
+		if !ok {
+			continue
+		}
+		result.valences++
+
+		if capsDiff && isUpper(words[i]) {
+			v += math.Copysign(capsIncrement, v)
+		}
+
+		for j := 1; j <= 3 && i-j >= 0; j++ {
+			prev := lower[i-j]
+
+			if b, ok := boosters[prev]; ok {
+				if _, isWord := lexicon[prev]; !isWord {
+					// b raises or lowers intensity, whichever way v points
+					scalar := b
+					if v < 0 {
+						scalar = -b
+					}
+					if capsDiff && isUpper(words[i-j]) {
+						scalar += math.Copysign(capsIncrement, v)
+					}
+					v += scalar * []float64{1, 0.95, 0.9}[j-1]
+				}
+			}
+
+			if isNegation(prev) {
+				v *= negationScalar
+			}
+		}
+
+		valences[i] = v
+	}
+
+	// Sentiment after "but" dominates what came before it
+	for i, word := range lower {
+		if word != "but" {
+			continue
+		}
+		for j := range valences {
+			switch {
+			case j < i:
+				valences[j] *= 0.5
+			case j > i:
+				valences[j] *= 1.5
+			}
+		}
+		break
+	}
+
+	// As in VADER, sentiment words weigh |v|+1 against 1 for a neutral word
+	var sum float64
+	for _, v := range valences {
+		sum += v
+		switch {
+		case v > 0:
+			result.positive += v + 1
+		case v < 0:
+			result.negative += 1 - v
+		default:
+			result.neutral++
+		}
+	}
+
+	if sum != 0 {
+		emphasis := math.Min(float64(strings.Count(sentence, "!")), 4) * exclamationBoost
+		sum += math.Copysign(emphasis, sum)
+		if sum > 0 {
+			result.positive += emphasis
+		} else {
+			result.negative += emphasis
+		}
+	}
+
+	result.compound = sum / math.Sqrt(sum*sum+normalizeAlpha)
+	return result
+}
+
+// splitSentences splits text after ., !, ? and line breaks, keeping the
+// punctuation with its sentence
+func splitSentences(text string) []string {
+	var sentences []string
+
+	start := 0
+	for i, r := range text {
+		switch r {
+		case '.', '!', '?', '\n':
+			// Runs such as "!!!" or "..." stay with their sentence
+			if next := i + 1; next < len(text) && strings.ContainsRune(".!?", rune(text[next])) {
+				continue
+			}
+			if s := strings.TrimSpace(text[start : i+1]); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(text[start:]); s != "" {
+		sentences = append(sentences, s)
+	}
+
+	return sentences
+}
+
+// tokenize splits on whitespace and trims punctuation around each word,
+// keeping inner apostrophes and hyphens as in "didn't" or "rip-off"
+func tokenize(sentence string) []string {
+	sentence = strings.ReplaceAll(sentence, "’", "'")
+
+	var words []string
+	for _, field := range strings.Fields(sentence) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+func isNegation(word string) bool {
+	return negations[strings.ReplaceAll(word, "'", "")] || strings.HasSuffix(word, "n't")
+}
+
+// isUpper reports whether word is shouted: two or more letters, all capitals
+func isUpper(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 1
+}
+
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
@@ -0,0 +1,135 @@
+package sentiment
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Scores
+	}{
+		{"empty", "", Scores{Neutral: 1}},
+		{"no lexicon words", "We stayed two nights in March.", Scores{Neutral: 1}},
+		{"one word", "good", Scores{Compound: 0.44, Positive: 1}},
+		{"neutral words share", "The room was good", Scores{Compound: 0.44, Positive: 0.492, Neutral: 0.508}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.text)
+			// Compare to two decimals, as VADER's published examples are
+			if math.Abs(got.Compound-tt.want.Compound) > 0.005 ||
+				math.Abs(got.Positive-tt.want.Positive) > 0.005 ||
+				math.Abs(got.Negative-tt.want.Negative) > 0.005 ||
+				math.Abs(got.Neutral-tt.want.Neutral) > 0.005 {
+				t.Errorf("Score(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		weaker, strong string // strong should score further from 0 than weaker
+	}{
+		{"booster", "The staff were helpful", "The staff were very helpful"},
+		{"dampener", "The bed was slightly uncomfortable", "The bed was uncomfortable"},
+		{"capitals", "The view was amazing", "The view was AMAZING"},
+		{"exclamation", "Great location", "Great location!!!"},
+		{"negative booster", "The room was dirty", "The room was extremely dirty"},
+		{"positive dampener", "The room was slightly nice", "The room was nice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weaker, strong := Score(tt.weaker).Compound, Score(tt.strong).Compound
+			if math.Signbit(weaker) != math.Signbit(strong) || math.Abs(strong) <= math.Abs(weaker) {
+				t.Errorf("Score(%q) = %v, want stronger than Score(%q) = %v", tt.strong, strong, tt.weaker, weaker)
+			}
+		})
+	}
+}
+
+func TestScorePolarity(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		sign int
+	}{
+		{"positive", "Excellent breakfast and lovely staff.", 1},
+		{"negative", "The bathroom was dirty and the staff were rude.", -1},
+		{"negation", "The room was not clean.", -1},
+		{"contraction", "The staff didn't help at all, not good.", -1},
+		{"curly apostrophe", "It wasn’t great.", -1},
+		{"but weighs the end", "The location is great but the room was dirty and awful.", -1},
+		{"but weighs the end positive", "The room was small but the staff were wonderful and helpful.", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Score(tt.text)
+			if sign := int(math.Copysign(1, got.Compound)); got.Compound == 0 || sign != tt.sign {
+				t.Errorf("Score(%q).Compound = %v, want sign %d", tt.text, got.Compound, tt.sign)
+			}
+			if sum := got.Positive + got.Negative + got.Neutral; math.Abs(sum-1) > 0.002 {
+				t.Errorf("Score(%q) shares add up to %v, want 1", tt.text, sum)
+			}
+		})
+	}
+}
+
+func TestScoreReview(t *testing.T) {
+	// A "but" in the disliked text must not reweigh the liked text
+	separate := ScoreReview("Lovely staff.", "Nothing, but parking was expensive.", "")
+	together := Score("Lovely staff. Nothing, but parking was expensive.")
+	if separate != together {
+		t.Errorf("ScoreReview() = %+v, want %+v", separate, together)
+	}
+
+	if got := ScoreReview("", "", ""); got != (Scores{Neutral: 1}) {
+		t.Errorf("ScoreReview() of empty review = %+v, want neutral", got)
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"One sentence", []string{"One sentence"}},
+		{"Great stay. Would return!", []string{"Great stay.", "Would return!"}},
+		{"Wow!!! Really?", []string{"Wow!!!", "Really?"}},
+		{"Hmm... ok\nNext line", []string{"Hmm...", "ok", "Next line"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitSentences(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("splitSentences(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		sentence string
+		want     []string
+	}{
+		{"", nil},
+		{"Great, clean room!", []string{"Great", "clean", "room"}},
+		{"It didn’t work", []string{"It", "didn't", "work"}},
+		{"a rip-off (really)", []string{"a", "rip-off", "really"}},
+		{"10/10 --", []string{"10/10"}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.sentence); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.sentence, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,29 @@
 package jsonl_processing
 
-import "time"
+import (
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/aspects"
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/dedupe"
+	"github.com/mahesh-singh/review-system/internal/roomtypes"
+)
 
 type ProcessingConfig struct {
-	BatchSize           int           // Number of records to process in each batch
-	MaxRetries          int           // Maximum number of retries for failed operations
-	RetryDelay          time.Duration // Delay between retries
-	MaxErrorsPercentage float64       // Maximum percentage of errors before stopping (0-100)
-	ContextTimeout      time.Duration // Timeout for database operations
+	BatchSize           int                 // Number of records to process in each batch
+	MaxRetries          int                 // Maximum number of retries for failed operations
+	RetryDelay          time.Duration       // Delay between retries
+	MaxErrorsPercentage float64             // Maximum percentage of errors before stopping (0-100)
+	ContextTimeout      time.Duration       // Timeout for database operations
+	ShutdownTimeout     time.Duration       // Time an in-flight batch gets to commit after cancellation
+	FailFast            bool                // Cancel the remaining files of a run once one file fails
+	MaxStoredErrors     int                 // Maximum record errors persisted per file run
+	Aspects             *aspects.Dictionary // Dictionary used to tag review aspects, nil for the embedded default
+	RoomTypes           *roomtypes.Rules    // Rules that categorize room type names, nil for the embedded default
+	Anomalies           data.AnomalyOptions // Rating anomaly detection run on the hotels of each file
+	DetectAnomalies     bool                // Run anomaly detection after each file
+	Dedupe              dedupe.Options      // What counts as a near-duplicate review
+	ClusterDuplicates   bool                // Recluster the hotels of each file for near-duplicates
 }
 
 func DefaultProcessingConfig() *ProcessingConfig {
@@ -17,6 +33,12 @@ func DefaultProcessingConfig() *ProcessingConfig {
 		RetryDelay:          time.Second * 2,
 		MaxErrorsPercentage: 10.0,
 		ContextTimeout:      time.Minute * 5,
+		ShutdownTimeout:     time.Second * 20,
+		MaxStoredErrors:     1000,
+		Anomalies:           data.DefaultAnomalyOptions(),
+		DetectAnomalies:     true,
+		Dedupe:              dedupe.DefaultOptions(),
+		ClusterDuplicates:   true,
 	}
 }
 
@@ -37,5 +59,23 @@ func ValidateConfig(config *ProcessingConfig) error {
 	if config.ContextTimeout <= 0 {
 		config.ContextTimeout = time.Minute * 5
 	}
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = time.Second * 20
+	}
+	if config.MaxStoredErrors <= 0 {
+		config.MaxStoredErrors = 1000
+	}
+	if config.Anomalies == (data.AnomalyOptions{}) {
+		config.Anomalies = data.DefaultAnomalyOptions()
+	}
+	if config.Dedupe == (dedupe.Options{}) {
+		config.Dedupe = dedupe.DefaultOptions()
+	}
+	if config.Aspects == nil {
+		config.Aspects = aspects.Default()
+	}
+	if config.RoomTypes == nil {
+		config.RoomTypes = roomtypes.Default()
+	}
 	return nil
 }
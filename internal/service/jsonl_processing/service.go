@@ -5,15 +5,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mahesh-singh/review-system/internal/data"
-	"github.com/mahesh-singh/review-system/internal/s3"
+	"github.com/mahesh-singh/review-system/internal/dedupe"
+	"github.com/mahesh-singh/review-system/internal/langid"
+	"github.com/mahesh-singh/review-system/internal/sentiment"
 )
 
 type JSONLProcessingService struct {
@@ -21,6 +25,8 @@ type JSONLProcessingService struct {
 	db     *sql.DB
 	models data.Models
 	logger *slog.Logger
+
+	onBatchCommit func(hotelIDs []int64)
 }
 
 func NewJSONLProcessingService(db *sql.DB, config *ProcessingConfig, logger *slog.Logger) *JSONLProcessingService {
@@ -40,36 +46,69 @@ func NewJSONLProcessingService(db *sql.DB, config *ProcessingConfig, logger *slo
 	}
 }
 
+// OnBatchCommit registers fn to be called after each batch with the hotels
+// whose records were committed, e.g. to invalidate cached responses. It
+// must be set before processing starts.
+func (s *JSONLProcessingService) OnBatchCommit(fn func(hotelIDs []int64)) {
+	s.onBatchCommit = fn
+}
+
 func (s *JSONLProcessingService) ProcessJSONLFile(
 	ctx context.Context,
 	reader io.Reader,
 	filename string,
 	s3Path string,
 ) (*ProcessingResult, error) {
+	return s.processFile(ctx, reader, FileToProcess{Filename: filename, S3Path: s3Path})
+}
+
+// processFile imports a single file, applying the file's platform override
+// to every record when one is set
+func (s *JSONLProcessingService) processFile(ctx context.Context, reader io.Reader, file FileToProcess) (*ProcessingResult, error) {
+	filename, s3Path := file.Filename, file.S3Path
+
 	// Check if file has already been processed
 	isProcessed, err := s.models.ProcessedFiles.IsProcessed(s3Path)
 	if err != nil {
 		return nil, fmt.Errorf("error checking if file is processed: %w", err)
 	}
 
-	if isProcessed {
+	if isProcessed && !file.Force {
 		log.Printf("File %s has already been processed, skipping", filename)
-		return &ProcessingResult{}, nil
+		return &ProcessingResult{Status: StatusSkipped}, nil
 	}
 
-	// Create processed file record
-	processedFile := &data.ProcessedFile{
-		Filename:    filename,
-		S3Path:      s3Path,
-		ProcessedAt: time.Now(),
-		Status:      "Processing",
-	}
+	// Resume an interrupted run from its last committed line, otherwise start a new record
+	processedFile, err := s.models.ProcessedFiles.GetInterrupted(s3Path)
+	switch {
+	case err == nil:
+		s.logger.Info("resuming interrupted file",
+			slog.String("file", filename),
+			slog.Int("last_committed_line", processedFile.LastCommittedLine))
+
+		processedFile.Status = data.FileStatusProcessing
+		if err := s.models.ProcessedFiles.Update(processedFile); err != nil {
+			return nil, fmt.Errorf("error updating processed file record: %w", err)
+		}
+	case errors.Is(err, data.ErrRecordNotFound):
+		processedFile = &data.ProcessedFile{
+			Filename:    filename,
+			S3Path:      s3Path,
+			ProcessedAt: time.Now(),
+			Status:      data.FileStatusProcessing,
+		}
 
-	err = s.models.ProcessedFiles.Create(processedFile)
-	if err != nil {
-		return nil, fmt.Errorf("error creating processed file record: %w", err)
+		if err := s.models.ProcessedFiles.Create(processedFile); err != nil {
+			return nil, fmt.Errorf("error creating processed file record: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("error checking for interrupted file: %w", err)
 	}
 
+	// Batches already running get ShutdownTimeout to commit once ctx is cancelled
+	batchCtx, cancelBatches := s.drainContext(ctx)
+	defer cancelBatches()
+
 	// Start processing
 	startTime := time.Now()
 	result := &ProcessingResult{
@@ -79,17 +118,40 @@ func (s *JSONLProcessingService) ProcessJSONLFile(
 	// Process file line by line
 	scanner := bufio.NewScanner(reader)
 	lineNumber := 0
-	batch := make([]*HotelReviewData, 0, s.config.BatchSize)
+	resumeFrom := processedFile.LastCommittedLine
+	lastCommittedLine := resumeFrom
+	batch := make([]batchRecord, 0, s.config.BatchSize)
+	touched := make(map[int64]struct{}) // Hotels with committed records
+
+	flushBatch := func() {
+		batchResult, handled := s.processBatch(batchCtx, batch, touched)
+		s.mergeBatchResult(result, batchResult)
+
+		// Everything before the first unhandled record is committed
+		if handled < len(batch) {
+			lastCommittedLine = batch[handled].LineNumber - 1
+		} else {
+			lastCommittedLine = lineNumber
+		}
+		batch = batch[:0] // Reset batch
+
+		// Show the run is alive, so its record isn't taken for a crashed one
+		if err := s.models.ProcessedFiles.Touch(processedFile.ID); err != nil {
+			s.logger.Error("warning: Failed to touch processed file record", slog.String("error", err.Error()))
+		}
+	}
 
 	for scanner.Scan() {
-		lineNumber++
-		result.TotalRecords++
+		// Stop taking new lines once shutdown has been requested
+		if ctx.Err() != nil {
+			break
+		}
 
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+		lineNumber++
+		if lineNumber <= resumeFrom {
+			continue
 		}
+		result.TotalRecords++
 
 		// Parse JSONL line
 		var reviewData HotelReviewData
@@ -98,19 +160,22 @@ func (s *JSONLProcessingService) ProcessJSONLFile(
 			result.ErrorRecords++
 			result.Errors = append(result.Errors, ProcessingError{
 				LineNumber: lineNumber,
+				Category:   ErrorCategoryJSONParse,
 				Error:      fmt.Sprintf("JSON parse error: %v", err),
 				RawData:    line,
 			})
 			continue
 		}
 
-		batch = append(batch, &reviewData)
+		if file.Platform != "" {
+			reviewData.Platform = file.Platform
+		}
+
+		batch = append(batch, batchRecord{LineNumber: lineNumber, Data: &reviewData})
 
 		// Process batch when it reaches the configured size
 		if len(batch) >= s.config.BatchSize {
-			batchResult := s.processBatch(ctx, batch)
-			s.mergeBatchResult(result, batchResult)
-			batch = batch[:0] // Reset batch
+			flushBatch()
 
 			// Check error percentage
 			if s.shouldStopProcessing(result) {
@@ -121,69 +186,281 @@ func (s *JSONLProcessingService) ProcessJSONLFile(
 		}
 	}
 
-	// Process remaining batch
+	// Process remaining batch, it has already been read so it is treated as in flight
 	if len(batch) > 0 {
-		batchResult := s.processBatch(ctx, batch)
-		s.mergeBatchResult(result, batchResult)
+		flushBatch()
 	}
 
-	if err := scanner.Err(); err != nil {
+	interrupted := ctx.Err() != nil
+
+	if err := scanner.Err(); err != nil && !interrupted {
 		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// Calculate duration and update processed file record
 	result.Duration = time.Since(startTime)
 
+	// Lines after the checkpoint are read again on resume, so they are left
+	// to that run rather than counted twice
+	if interrupted {
+		dropUncommittedErrors(result, lastCommittedLine)
+		result.TotalRecords = lastCommittedLine - resumeFrom
+	}
+
+	// Counts accumulate across resumed runs of the same file
+	processedFile.RecordsCount += result.SuccessRecords
+	processedFile.ErrorsCount += result.ErrorRecords
+	processedFile.LastCommittedLine = lastCommittedLine
+
 	// Determine final status
-	status := "Success"
-	if result.ErrorRecords > 0 {
-		if result.SuccessRecords == 0 {
-			status = "Failed"
+	status := data.FileStatusSuccess
+	switch {
+	case interrupted:
+		status = data.FileStatusInterrupted
+	case processedFile.ErrorsCount > 0:
+		if processedFile.RecordsCount == 0 {
+			status = data.FileStatusFailed
 		} else {
-			status = "Partial"
+			status = data.FileStatusPartial
 		}
 	}
-
-	// Update processed file record
-	processedFile.RecordsCount = result.SuccessRecords
-	processedFile.ErrorsCount = result.ErrorRecords
 	processedFile.Status = status
+	result.Status = status
 
 	if err := s.models.ProcessedFiles.Update(processedFile); err != nil {
 		s.logger.Error("warning: Failed to update processed file record", slog.String("error", err.Error()))
 	}
 
+	if err := s.saveErrors(processedFile.ID, result.Errors); err != nil {
+		s.logger.Error("warning: Failed to save processing errors", slog.String("error", err.Error()))
+	}
+
+	if !interrupted {
+		s.clusterDuplicates(ctx, touched)
+		s.detectAnomalies(ctx, touched)
+	}
+
+	if interrupted {
+		s.logger.Warn("processing interrupted",
+			slog.String("file", filename),
+			slog.Int("last_committed_line", lastCommittedLine))
+		return result, fmt.Errorf("processing of %s interrupted after line %d: %w", filename, lastCommittedLine, ctx.Err())
+	}
+
 	s.logger.Error(fmt.Errorf("processing completed for %s: %d total, %d success, %d errors in %v",
 		filename, result.TotalRecords, result.SuccessRecords, result.ErrorRecords, result.Duration).Error())
 
 	return result, nil
 }
 
-// processBatch processes a batch of hotel review data
-func (s *JSONLProcessingService) processBatch(ctx context.Context, batch []*HotelReviewData) *ProcessingResult {
+// saveErrors persists up to MaxStoredErrors record errors for the file so
+// they can be browsed after the run
+func (s *JSONLProcessingService) saveErrors(fileID int64, processingErrors []ProcessingError) error {
+	if len(processingErrors) > s.config.MaxStoredErrors {
+		processingErrors = processingErrors[:s.config.MaxStoredErrors]
+	}
+
+	records := make([]*data.ProcessingError, 0, len(processingErrors))
+	for _, processingError := range processingErrors {
+		records = append(records, &data.ProcessingError{
+			LineNumber: processingError.LineNumber,
+			Category:   processingError.Category,
+			Message:    processingError.Error,
+			RawData:    processingError.RawData,
+		})
+	}
+
+	return s.models.ProcessingErrors.InsertMany(fileID, records)
+}
+
+// ReprocessFile imports a single file again even if an earlier run already
+// processed it. Records are upserted, so rerunning a file is safe.
+func (s *JSONLProcessingService) ReprocessFile(ctx context.Context, file FileToProcess) (*ProcessingResult, error) {
+	reader, err := file.Reader.GetReader(ctx, file.S3Path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer reader.Close()
+
+	file.Force = true
+	return s.processFile(ctx, reader, file)
+}
+
+// processBatch processes a batch of hotel review data and reports how many
+// records were handled before ctx was done. Hotels with committed records
+// are added to touched.
+func (s *JSONLProcessingService) processBatch(ctx context.Context, batch []batchRecord, touched map[int64]struct{}) (*ProcessingResult, int) {
 	result := &ProcessingResult{
 		Errors: make([]ProcessingError, 0),
 	}
 
-	for _, reviewData := range batch {
+	committed := make(map[data.DailyStatsKey]struct{})
+	defer s.afterBatchCommit(ctx, committed)
+
+	for i, record := range batch {
 		select {
 		case <-ctx.Done():
-			return result
+			return result, i
 		default:
 		}
 
-		if err := s.processHotelReviewData(ctx, reviewData); err != nil {
+		if err := s.processHotelReviewData(ctx, record.Data); err != nil {
+			// A rollback caused by the shutdown deadline is not a record error
+			if ctx.Err() != nil {
+				return result, i
+			}
 			result.ErrorRecords++
 			result.Errors = append(result.Errors, ProcessingError{
-				Error: fmt.Sprintf("Processing error for hotel %d: %v", reviewData.HotelID, err),
+				LineNumber: record.LineNumber,
+				Category:   categorizeError(err),
+				Error:      fmt.Sprintf("Processing error for hotel %d: %v", record.Data.HotelID, err),
 			})
 		} else {
 			result.SuccessRecords++
+			// review_date is stored without its zone, so the day is the wall clock date
+			y, m, d := record.Data.Comment.ReviewDate.Date()
+			day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+			committed[data.DailyStatsKey{HotelID: record.Data.HotelID, Day: day}] = struct{}{}
+			touched[record.Data.HotelID] = struct{}{}
 		}
 		result.TotalRecords++
 	}
 
-	return result
+	return result, len(batch)
+}
+
+// afterBatchCommit refreshes the daily rollup for the hotel days a batch
+// committed and then notifies the OnBatchCommit callback
+func (s *JSONLProcessingService) afterBatchCommit(ctx context.Context, committed map[data.DailyStatsKey]struct{}) {
+	if len(committed) == 0 {
+		return
+	}
+
+	keys := make([]data.DailyStatsKey, 0, len(committed))
+	hotels := make(map[int64]struct{})
+	for key := range committed {
+		keys = append(keys, key)
+		hotels[key.HotelID] = struct{}{}
+	}
+
+	// A failed refresh leaves the rollup stale until `rollup rebuild`, it
+	// doesn't fail the records
+	if err := s.refreshDailyStats(ctx, keys); err != nil {
+		s.logger.Error("warning: Failed to refresh daily stats", slog.Int("hotels", len(hotels)), slog.String("error", err.Error()))
+	}
+
+	if s.onBatchCommit == nil {
+		return
+	}
+
+	hotelIDs := make([]int64, 0, len(hotels))
+	for hotelID := range hotels {
+		hotelIDs = append(hotelIDs, hotelID)
+	}
+
+	s.onBatchCommit(hotelIDs)
+}
+
+func (s *JSONLProcessingService) refreshDailyStats(ctx context.Context, keys []data.DailyStatsKey) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := (data.DailyStatsModel{DB: tx}).Refresh(ctx, keys); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// clusterDuplicates reclusters the near-duplicate reviews of the hotels a
+// file touched and refreshes the rollup days whose counts changed. A
+// failure is logged and doesn't fail the file; `dedupe backfill` can be
+// run to catch up.
+func (s *JSONLProcessingService) clusterDuplicates(ctx context.Context, touched map[int64]struct{}) {
+	if !s.config.ClusterDuplicates {
+		return
+	}
+
+	for hotelID := range touched {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.reclusterHotel(ctx, hotelID); err != nil {
+			s.logger.Error("warning: Failed to cluster duplicate reviews", slog.Int64("hotel_id", hotelID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (s *JSONLProcessingService) reclusterHotel(ctx context.Context, hotelID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	keys, err := data.DuplicateModel{DB: tx}.Recluster(ctx, hotelID, s.config.Dedupe)
+	if err != nil {
+		return err
+	}
+
+	if err := (data.DailyStatsModel{DB: tx}).Refresh(ctx, keys); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// detectAnomalies checks the hotels a file touched for rating anomalies. A
+// failure is logged and doesn't fail the file; `alerts detect` can be run
+// to catch up.
+func (s *JSONLProcessingService) detectAnomalies(ctx context.Context, touched map[int64]struct{}) {
+	if !s.config.DetectAnomalies || len(touched) == 0 {
+		return
+	}
+
+	hotelIDs := make([]int64, 0, len(touched))
+	for hotelID := range touched {
+		hotelIDs = append(hotelIDs, hotelID)
+	}
+
+	alerts, err := s.models.Alerts.Detect(ctx, hotelIDs, s.config.Anomalies)
+	if err != nil {
+		s.logger.Error("warning: Failed to detect rating anomalies", slog.Int("hotels", len(hotelIDs)), slog.String("error", err.Error()))
+		return
+	}
+
+	for _, alert := range alerts {
+		s.logger.Warn("rating anomaly",
+			slog.Int64("hotel_id", alert.HotelID),
+			slog.String("kind", alert.Kind),
+			slog.String("severity", alert.Severity),
+			slog.String("message", alert.Message))
+	}
+}
+
+// drainContext returns a context that outlives ctx by ShutdownTimeout, so a
+// batch that is already running can commit after a shutdown signal
+func (s *JSONLProcessingService) drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(s.config.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cancel()
+		case <-drainCtx.Done():
+		}
+	})
+
+	return drainCtx, func() {
+		stop()
+		cancel()
+	}
 }
 
 func (s *JSONLProcessingService) processHotelReviewData(ctx context.Context, reviewData *HotelReviewData) error {
@@ -236,6 +513,8 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 	providerModel := &data.ProviderModel{DB: tx}
 	countryModel := &data.CountryModel{DB: tx}
 	reviewGroupModel := &data.ReviewGroupModel{DB: tx}
+	roomTypeModel := &data.RoomTypeModel{DB: tx}
+	reviewAspectModel := &data.ReviewAspectModel{DB: tx}
 
 	// 1. Process Hotel
 	hotel := &data.Hotel{
@@ -248,8 +527,9 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 		return fmt.Errorf("failed to create/update hotel: %w", err)
 	}
 
-	// 2. Process Provider for review
-	provider, err := providerModel.CreateOrGet(reviewData.Comment.ReviewProviderText)
+	// 2. Process Provider for review. Lookups match the platform's external
+	// ids first so renames don't create new rows, then fall back to the name.
+	provider, err := providerModel.Resolve(reviewData.Platform, reviewData.Comment.ProviderID, reviewData.Comment.ReviewProviderText)
 	if err != nil {
 		return fmt.Errorf("failed to get/create provider: %w", err)
 	}
@@ -259,7 +539,9 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 	var reviewGroupID *int
 
 	if reviewData.Comment.ReviewerInfo.CountryName != "" {
-		country, err := countryModel.CreateOrGet(
+		country, err := countryModel.Resolve(
+			reviewData.Platform,
+			reviewData.Comment.ReviewerInfo.CountryID,
 			reviewData.Comment.ReviewerInfo.CountryName,
 			reviewData.Comment.ReviewerInfo.FlagName,
 		)
@@ -270,14 +552,65 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 	}
 
 	if reviewData.Comment.ReviewerInfo.ReviewGroupName != "" {
-		reviewGroup, err := reviewGroupModel.CreateOrGet(reviewData.Comment.ReviewerInfo.ReviewGroupName)
+		reviewGroup, err := reviewGroupModel.Resolve(
+			reviewData.Platform,
+			reviewData.Comment.ReviewerInfo.ReviewGroupID,
+			reviewData.Comment.ReviewerInfo.ReviewGroupName,
+		)
 		if err != nil {
 			return fmt.Errorf("failed to get/create review group: %w", err)
 		}
 		reviewGroupID = &reviewGroup.ID
 	}
 
+	// Room types are kept per hotel and provider, with a canonical category
+	var roomTypeID *int
+	if name := reviewData.Comment.ReviewerInfo.RoomTypeName; name != "" {
+		roomType := &data.RoomType{
+			HotelID:            reviewData.HotelID,
+			ProviderID:         provider.ID,
+			ProviderRoomTypeID: reviewData.Comment.ReviewerInfo.RoomTypeID,
+			Name:               name,
+			Category:           s.config.RoomTypes.Categorize(name),
+			RulesVersion:       s.config.RoomTypes.Version,
+		}
+		if err := roomTypeModel.CreateOrGet(roomType); err != nil {
+			return fmt.Errorf("failed to get/create room type: %w", err)
+		}
+		roomTypeID = &roomType.ID
+	}
+
 	// 4. Process Review
+	scores := sentiment.ScoreReview(
+		reviewData.Comment.ReviewPositives,
+		reviewData.Comment.ReviewNegatives,
+		reviewData.Comment.ReviewComments,
+	)
+	sentimentVersion := sentiment.Version
+
+	var simhash *int64
+	if signature, ok := dedupe.Sign(
+		reviewData.Comment.ReviewPositives,
+		reviewData.Comment.ReviewNegatives,
+		reviewData.Comment.ReviewComments,
+	); ok {
+		value := int64(signature)
+		simhash = &value
+	}
+	simhashVersion := dedupe.Version
+
+	var language *string
+	var languageConfidence *float64
+	if result, ok := langid.DetectReview(
+		reviewData.Comment.OriginalComment,
+		reviewData.Comment.ReviewComments,
+		reviewData.Comment.ReviewPositives,
+		reviewData.Comment.ReviewNegatives,
+	); ok {
+		language, languageConfidence = &result.Language, &result.Confidence
+	}
+	languageVersion := langid.Version
+
 	review := &data.Review{
 		HotelReviewID:           reviewData.Comment.HotelReviewID,
 		HotelID:                 reviewData.HotelID,
@@ -311,6 +644,7 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 		ReviewerFlagName:        reviewData.Comment.ReviewerInfo.FlagName,
 		ReviewerGroupName:       reviewData.Comment.ReviewerInfo.ReviewGroupName,
 		ReviewerRoomTypeName:    reviewData.Comment.ReviewerInfo.RoomTypeName,
+		RoomTypeID:              roomTypeID,
 		ReviewerCountryID:       countryID,
 		ReviewerLengthOfStay:    reviewData.Comment.ReviewerInfo.LengthOfStay,
 		ReviewerGroupID:         reviewGroupID,
@@ -318,16 +652,34 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 		ReviewerIsExpert:        reviewData.Comment.ReviewerInfo.IsExpertReviewer,
 		ReviewerShowGlobalIcon:  reviewData.Comment.ReviewerInfo.IsShowGlobalIcon,
 		ReviewerShowReviewCount: reviewData.Comment.ReviewerInfo.IsShowReviewedCount,
+
+		SentimentScore:   &scores.Compound,
+		SentimentVersion: &sentimentVersion,
+		TextSimhash:      simhash,
+		SimhashVersion:   &simhashVersion,
+
+		Language:           language,
+		LanguageConfidence: languageConfidence,
+		LanguageVersion:    &languageVersion,
 	}
 
 	if err := reviewModel.Create(review); err != nil {
 		return fmt.Errorf("failed to create/update review: %w", err)
 	}
 
-	// 5. Process Hotel Provider Ratings
+	// 5. Tag the aspects guests liked and disliked
+	tagged := data.TaggedReview{
+		ReviewID: review.ID,
+		Mentions: s.config.Aspects.Extract(review.ReviewPositives, review.ReviewNegatives),
+	}
+	if err := reviewAspectModel.Replace(ctx, s.config.Aspects.Version, []data.TaggedReview{tagged}); err != nil {
+		return fmt.Errorf("failed to store review aspects: %w", err)
+	}
+
+	// 6. Process Hotel Provider Ratings
 	for _, providerRating := range reviewData.OverallByProviders {
 		// Get or create provider for rating
-		ratingProvider, err := providerModel.CreateOrGet(providerRating.Provider)
+		ratingProvider, err := providerModel.Resolve(reviewData.Platform, providerRating.ProviderID, providerRating.Provider)
 		if err != nil {
 			return fmt.Errorf("failed to get/create rating provider: %w", err)
 		}
@@ -354,62 +706,144 @@ func (s *JSONLProcessingService) processInTransaction(ctx context.Context, tx *s
 	return nil
 }
 
-// ProcessMultipleFiles processes multiple JSONL files concurrently
+// ProcessMultipleFiles processes multiple JSONL files with a fixed pool of
+// maxConcurrency workers fed from a queue. Files may come from different
+// sources, each read through its own FileReader. Results are keyed by S3
+// path; files that failed are reported together in a *MultiFileError. With
+// FailFast set, the first failed file cancels the rest of the run. All
+// workers have returned by the time ProcessMultipleFiles returns.
 func (s *JSONLProcessingService) ProcessMultipleFiles(
 	ctx context.Context,
-	files []s3.FileInfo,
-	fileReader s3.FileReader,
+	files []FileToProcess,
 	maxConcurrency int,
 ) (map[string]*ProcessingResult, error) {
-	semaphore := make(chan struct{}, maxConcurrency)
-	results := make(map[string]*ProcessingResult)
-	resultsChan := make(chan FileResult, len(files))
-	errorsChan := make(chan error, len(files))
-
-	// Process files concurrently
-	for _, file := range files {
-		go func(f s3.FileInfo) {
-			semaphore <- struct{}{}        // Acquire semaphore
-			defer func() { <-semaphore }() // Release semaphore
-
-			reader, err := fileReader.GetReader(ctx, file.S3Path)
-			if err != nil {
-				errorsChan <- fmt.Errorf("error processing %s: %w", f.Key, err)
+	results := make(map[string]*ProcessingResult, len(files))
+	if len(files) == 0 {
+		return results, ctx.Err()
+	}
+
+	workers := maxConcurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan FileToProcess)
+	resultsChan := make(chan FileResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range queue {
+				// A file dequeued after cancellation is left unstarted
+				if runCtx.Err() != nil {
+					continue
+				}
+				resultsChan <- s.processQueuedFile(runCtx, f)
+			}
+		}()
+	}
+
+	// Feed the queue until the files run out or the run is cancelled
+	go func() {
+		defer close(queue)
+		for _, f := range files {
+			// select picks at random when both cases are ready
+			if runCtx.Err() != nil {
 				return
 			}
-			result, err := s.ProcessJSONLFile(ctx, reader, f.Key, f.S3Path)
-			if err != nil {
-				errorsChan <- fmt.Errorf("error processing %s: %w", f.Key, err)
+			select {
+			case queue <- f:
+			case <-runCtx.Done():
 				return
 			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultsChan)
+	}()
+
+	// Collect results, waiting for in-flight files so they can record their state
+	var fileErrors []*FileError
+	for result := range resultsChan {
+		results[result.Filename] = result.Result
+		if result.Err == nil {
+			continue
+		}
 
-			resultsChan <- FileResult{
-				Filename: f.Key,
-				Result:   result,
-			}
-		}(file)
+		fileErrors = append(fileErrors, &FileError{S3Path: result.Filename, Err: result.Err})
+		if s.config.FailFast && runCtx.Err() == nil {
+			s.logger.Warn("fail-fast: cancelling remaining files", slog.String("file", result.Filename))
+			cancel()
+		}
 	}
 
-	// Collect results
-	completed := 0
-	var firstError error
-
-	for completed < len(files) {
-		select {
-		case result := <-resultsChan:
-			results[result.Filename] = result.Result
-			completed++
-		case err := <-errorsChan:
-			if firstError == nil {
-				firstError = err
-			}
-			completed++
-		case <-ctx.Done():
-			return results, ctx.Err()
+	// Files still in the queue when the run was cancelled were never started
+	for _, f := range files {
+		if _, ok := results[f.S3Path]; !ok {
+			results[f.S3Path] = &ProcessingResult{Status: StatusNotStarted, Source: f.Source}
 		}
 	}
 
-	return results, firstError
+	if len(fileErrors) > 0 {
+		return results, &MultiFileError{Errors: fileErrors}
+	}
+
+	return results, ctx.Err()
+}
+
+// processQueuedFile reads and imports one file for a ProcessMultipleFiles worker
+func (s *JSONLProcessingService) processQueuedFile(ctx context.Context, f FileToProcess) FileResult {
+	startTime := time.Now()
+	reader, err := f.Reader.GetReader(ctx, f.S3Path)
+	if err != nil {
+		return fileError(f, nil, fmt.Errorf("error opening file: %w", err))
+	}
+	defer reader.Close()
+
+	result, err := s.processFile(ctx, reader, f)
+	if err != nil {
+		fileErr := fileError(f, result, err)
+		fileErr.Result.Duration = time.Since(startTime)
+		return fileErr
+	}
+	result.Source = f.Source
+
+	return FileResult{
+		Filename: f.S3Path,
+		Result:   result,
+	}
+}
+
+// fileError builds the FileResult for a file that could not be processed, so
+// every file still appears in the results with a status and its error
+func fileError(f FileToProcess, result *ProcessingResult, err error) FileResult {
+	if result == nil {
+		result = &ProcessingResult{Status: data.FileStatusFailed}
+	}
+	if result.Status == "" {
+		result.Status = data.FileStatusFailed
+	}
+	result.Source = f.Source
+	result.Errors = append(result.Errors, ProcessingError{
+		Category: ErrorCategoryFile,
+		Error:    err.Error(),
+	})
+
+	return FileResult{
+		Filename: f.S3Path,
+		Result:   result,
+		Err:      err,
+	}
 }
 
 // GetProcessedFileStatus returns the status of a processed file
@@ -423,6 +857,20 @@ func (s *JSONLProcessingService) mergeBatchResult(main *ProcessingResult, batch
 	main.Errors = append(main.Errors, batch.Errors...)
 }
 
+// dropUncommittedErrors removes the errors of lines after lastCommittedLine
+// from result, along with their counts
+func dropUncommittedErrors(result *ProcessingResult, lastCommittedLine int) {
+	kept := result.Errors[:0]
+	for _, e := range result.Errors {
+		if e.LineNumber <= lastCommittedLine {
+			kept = append(kept, e)
+			continue
+		}
+		result.ErrorRecords--
+	}
+	result.Errors = kept
+}
+
 func (s *JSONLProcessingService) shouldStopProcessing(result *ProcessingResult) bool {
 	if result.TotalRecords < 100 { // Don't stop early if we haven't processed enough records
 		return false
@@ -432,6 +880,36 @@ func (s *JSONLProcessingService) shouldStopProcessing(result *ProcessingResult)
 	return errorPercentage > s.config.MaxErrorsPercentage
 }
 
+// categorizeError maps a record processing error to the step that failed,
+// based on the messages wrapped in processInTransaction
+func categorizeError(err error) string {
+	errorStr := strings.ToLower(err.Error())
+
+	categories := []struct {
+		match    string
+		category string
+	}{
+		{"context deadline exceeded", ErrorCategoryTimeout},
+		{"transaction", ErrorCategoryTransaction},
+		{"hotel provider rating", ErrorCategoryProviderRating},
+		{"rating provider", ErrorCategoryProvider},
+		{"create/update hotel", ErrorCategoryHotel},
+		{"provider", ErrorCategoryProvider},
+		{"country", ErrorCategoryCountry},
+		{"review group", ErrorCategoryReviewGroup},
+		{"room type", ErrorCategoryRoomType},
+		{"review", ErrorCategoryReview},
+	}
+
+	for _, c := range categories {
+		if strings.Contains(errorStr, c.match) {
+			return c.category
+		}
+	}
+
+	return ErrorCategoryOther
+}
+
 func (s *JSONLProcessingService) isRetryableError(err error) bool {
 	// Database connection errors, timeout errors, etc. are retryable
 	// Constraint violations, JSON parsing errors, etc. are not retryable
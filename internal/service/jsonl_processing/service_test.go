@@ -0,0 +1,270 @@
+package jsonl_processing
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+	"github.com/mahesh-singh/review-system/internal/data/datatest"
+)
+
+func TestDropUncommittedErrors(t *testing.T) {
+	errs := []ProcessingError{
+		{LineNumber: 3, Category: ErrorCategoryJSONParse},
+		{LineNumber: 10, Category: ErrorCategoryHotel},
+		{LineNumber: 11, Category: ErrorCategoryJSONParse},
+		{LineNumber: 15, Category: ErrorCategoryReview},
+	}
+
+	tests := []struct {
+		name              string
+		lastCommittedLine int
+		wantLines         []int
+	}{
+		{"all committed", 20, []int{3, 10, 11, 15}},
+		{"checkpoint on an error line", 10, []int{3, 10}},
+		{"checkpoint between errors", 12, []int{3, 10, 11}},
+		{"nothing committed", 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ProcessingResult{
+				SuccessRecords: 5,
+				ErrorRecords:   len(errs),
+				Errors:         append([]ProcessingError(nil), errs...),
+			}
+
+			dropUncommittedErrors(result, tt.lastCommittedLine)
+
+			lines := []int{}
+			for _, e := range result.Errors {
+				lines = append(lines, e.LineNumber)
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("kept lines %v, want %v", lines, tt.wantLines)
+			}
+			if result.ErrorRecords != len(tt.wantLines) {
+				t.Errorf("ErrorRecords = %d, want %d", result.ErrorRecords, len(tt.wantLines))
+			}
+			if result.SuccessRecords != 5 {
+				t.Errorf("SuccessRecords = %d, want 5", result.SuccessRecords)
+			}
+		})
+	}
+}
+
+var errOpen = errors.New("access denied")
+
+// stubReader opens every file as empty, except those in fail, which fail
+// to open, and those in block, which open once ctx is done
+type stubReader struct {
+	fail, block []string
+
+	mu            sync.Mutex
+	open, maxOpen int
+}
+
+func (r *stubReader) GetReader(ctx context.Context, path string) (io.ReadCloser, error) {
+	r.mu.Lock()
+	r.open++
+	r.maxOpen = max(r.maxOpen, r.open)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.open--
+		r.mu.Unlock()
+	}()
+
+	switch {
+	case slices.Contains(r.fail, path):
+		return nil, errOpen
+	case slices.Contains(r.block, path):
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func newTestService(t *testing.T, failFast bool) *JSONLProcessingService {
+	t.Helper()
+
+	db, _ := datatest.Open(datatest.Match(datatest.Case{
+		Parts: []string{"INSERT INTO processed_files"},
+		Result: datatest.Result{
+			Columns: []string{"id", "created_at", "updated_at"},
+			Rows:    [][]driver.Value{{int64(1), time.Now(), time.Now()}},
+		},
+	}))
+	t.Cleanup(func() { db.Close() })
+
+	config := DefaultProcessingConfig()
+	config.FailFast = failFast
+	return NewJSONLProcessingService(db, config, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestProcessMultipleFiles(t *testing.T) {
+	const success, failed, notStarted = data.FileStatusSuccess, data.FileStatusFailed, StatusNotStarted
+
+	tests := []struct {
+		name        string
+		files       []string
+		fail, block []string
+		concurrency int
+		failFast    bool
+		cancelled   bool          // Cancel ctx before the run
+		timeout     time.Duration // Deadline of ctx, none when zero
+		want        map[string][]string
+		wantFailed  []string // Files the MultiFileError must name
+		wantErr     error    // Must match the returned error with errors.Is
+	}{
+		{
+			name:        "all succeed",
+			files:       []string{"a", "b", "c"},
+			concurrency: 2,
+			want:        map[string][]string{"a": {success}, "b": {success}, "c": {success}},
+		},
+		{
+			name:        "failure is reported with the others",
+			files:       []string{"a", "b", "c"},
+			fail:        []string{"b"},
+			concurrency: 2,
+			want:        map[string][]string{"a": {success}, "b": {failed}, "c": {success}},
+			wantFailed:  []string{"b"},
+			wantErr:     errOpen,
+		},
+		{
+			name:        "several failures",
+			files:       []string{"a", "b", "c"},
+			fail:        []string{"a", "c"},
+			concurrency: 3,
+			want:        map[string][]string{"a": {failed}, "b": {success}, "c": {failed}},
+			wantFailed:  []string{"a", "c"},
+			wantErr:     errOpen,
+		},
+		{
+			name:        "fail fast leaves the rest unstarted",
+			files:       []string{"a", "b", "c"},
+			fail:        []string{"a"},
+			block:       []string{"b"},
+			concurrency: 1,
+			failFast:    true,
+			// b may be dequeued before the cancellation lands, then its open fails
+			want:       map[string][]string{"a": {failed}, "b": {notStarted, failed}, "c": {notStarted}},
+			wantFailed: []string{"a"},
+			wantErr:    errOpen,
+		},
+		{
+			name:        "cancelled before the run",
+			files:       []string{"a", "b"},
+			concurrency: 2,
+			cancelled:   true,
+			want:        map[string][]string{"a": {notStarted}, "b": {notStarted}},
+			wantErr:     context.Canceled,
+		},
+		{
+			name:        "deadline stops blocked files",
+			files:       []string{"a", "b"},
+			block:       []string{"a", "b"},
+			concurrency: 2,
+			timeout:     20 * time.Millisecond,
+			want:        map[string][]string{"a": {failed}, "b": {failed}},
+			wantFailed:  []string{"a", "b"},
+			wantErr:     context.DeadlineExceeded,
+		},
+		{
+			name:        "no files",
+			concurrency: 2,
+			want:        map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, tt.failFast)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.timeout > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), tt.timeout)
+			}
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			reader := &stubReader{fail: tt.fail, block: tt.block}
+			files := make([]FileToProcess, 0, len(tt.files))
+			for _, path := range tt.files {
+				files = append(files, FileToProcess{Filename: path, S3Path: path, Source: "test", Reader: reader})
+			}
+
+			results, err := s.ProcessMultipleFiles(ctx, files, tt.concurrency)
+
+			if len(results) != len(tt.want) {
+				t.Errorf("got %d results, want %d", len(results), len(tt.want))
+			}
+			for path, statuses := range tt.want {
+				result, ok := results[path]
+				if !ok {
+					t.Errorf("%s: no result", path)
+					continue
+				}
+				if !slices.Contains(statuses, result.Status) {
+					t.Errorf("%s: status %q, want one of %q", path, result.Status, statuses)
+				}
+				if result.Source != "test" {
+					t.Errorf("%s: source %q, want %q", path, result.Source, "test")
+				}
+			}
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			var multi *MultiFileError
+			if len(tt.wantFailed) > 0 && !errors.As(err, &multi) {
+				t.Fatalf("err = %T, want *MultiFileError", err)
+			}
+			for _, path := range tt.wantFailed {
+				if !slices.ContainsFunc(multi.Errors, func(e *FileError) bool { return e.S3Path == path }) {
+					t.Errorf("MultiFileError doesn't name %s: %v", path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessMultipleFilesConcurrency(t *testing.T) {
+	s := newTestService(t, false)
+
+	paths := []string{"a", "b", "c", "d", "e"}
+	reader := &stubReader{block: paths}
+	files := make([]FileToProcess, 0, len(paths))
+	for _, path := range paths {
+		files = append(files, FileToProcess{Filename: path, S3Path: path, Reader: reader})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s.ProcessMultipleFiles(ctx, files, 2)
+
+	if reader.maxOpen != 2 {
+		t.Errorf("opened %d files at once, want 2", reader.maxOpen)
+	}
+}
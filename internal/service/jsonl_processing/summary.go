@@ -0,0 +1,155 @@
+package jsonl_processing
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+// maxSummaryErrorCategories limits how many error categories a RunSummary lists
+const maxSummaryErrorCategories = 10
+
+// RunSummary is the machine-readable outcome of a ProcessMultipleFiles run
+type RunSummary struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Outcome    string    `json:"outcome"`
+	Error      string    `json:"error,omitempty"`
+	RunTotals
+	TopErrors []ErrorCategoryCount `json:"top_errors"`
+	Sources   []SourceSummary      `json:"sources"`
+	Files     []FileSummary        `json:"files"`
+}
+
+// RunTotals counts files by status and records by outcome
+type RunTotals struct {
+	TotalFiles       int `json:"total_files"`
+	SuccessFiles     int `json:"success_files"`
+	PartialFiles     int `json:"partial_files"`
+	FailedFiles      int `json:"failed_files"`
+	SkippedFiles     int `json:"skipped_files"`
+	InterruptedFiles int `json:"interrupted_files"`
+	NotStartedFiles  int `json:"not_started_files"`
+	TotalRecords     int `json:"total_records"`
+	SuccessRecords   int `json:"success_records"`
+	ErrorRecords     int `json:"error_records"`
+}
+
+type SourceSummary struct {
+	Name string `json:"name"`
+	RunTotals
+}
+
+type FileSummary struct {
+	S3Path         string `json:"s3_path"`
+	Source         string `json:"source,omitempty"`
+	Status         string `json:"status"`
+	TotalRecords   int    `json:"total_records"`
+	SuccessRecords int    `json:"success_records"`
+	ErrorRecords   int    `json:"error_records"`
+	DurationMS     int64  `json:"duration_ms"`
+}
+
+type ErrorCategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+func (t *RunTotals) add(result *ProcessingResult) {
+	t.TotalFiles++
+	t.TotalRecords += result.TotalRecords
+	t.SuccessRecords += result.SuccessRecords
+	t.ErrorRecords += result.ErrorRecords
+
+	switch result.Status {
+	case data.FileStatusSuccess:
+		t.SuccessFiles++
+	case data.FileStatusPartial:
+		t.PartialFiles++
+	case StatusSkipped:
+		t.SkippedFiles++
+	case data.FileStatusInterrupted:
+		t.InterruptedFiles++
+	case StatusNotStarted:
+		t.NotStartedFiles++
+	default:
+		t.FailedFiles++
+	}
+}
+
+// NewRunSummary aggregates per-file results, keyed by S3 path, into a
+// RunSummary. Outcome is left for the caller, which knows the thresholds
+// that apply to the run.
+func NewRunSummary(startedAt time.Time, results map[string]*ProcessingResult) *RunSummary {
+	finishedAt := time.Now()
+	summary := &RunSummary{
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		DurationMS: finishedAt.Sub(startedAt).Milliseconds(),
+		TopErrors:  make([]ErrorCategoryCount, 0),
+		Sources:    make([]SourceSummary, 0),
+		Files:      make([]FileSummary, 0, len(results)),
+	}
+
+	categoryCounts := make(map[string]int)
+	sources := make(map[string]*SourceSummary)
+
+	for s3Path, result := range results {
+		summary.add(result)
+
+		if result.Source != "" {
+			source, ok := sources[result.Source]
+			if !ok {
+				source = &SourceSummary{Name: result.Source}
+				sources[result.Source] = source
+			}
+			source.add(result)
+		}
+
+		for _, processingError := range result.Errors {
+			category := processingError.Category
+			if category == "" {
+				category = ErrorCategoryOther
+			}
+			categoryCounts[category]++
+		}
+
+		summary.Files = append(summary.Files, FileSummary{
+			S3Path:         s3Path,
+			Source:         result.Source,
+			Status:         result.Status,
+			TotalRecords:   result.TotalRecords,
+			SuccessRecords: result.SuccessRecords,
+			ErrorRecords:   result.ErrorRecords,
+			DurationMS:     result.Duration.Milliseconds(),
+		})
+	}
+
+	sort.Slice(summary.Files, func(i, j int) bool {
+		return summary.Files[i].S3Path < summary.Files[j].S3Path
+	})
+
+	for _, source := range sources {
+		summary.Sources = append(summary.Sources, *source)
+	}
+	sort.Slice(summary.Sources, func(i, j int) bool {
+		return summary.Sources[i].Name < summary.Sources[j].Name
+	})
+
+	for category, count := range categoryCounts {
+		summary.TopErrors = append(summary.TopErrors, ErrorCategoryCount{Category: category, Count: count})
+	}
+	sort.Slice(summary.TopErrors, func(i, j int) bool {
+		if summary.TopErrors[i].Count != summary.TopErrors[j].Count {
+			return summary.TopErrors[i].Count > summary.TopErrors[j].Count
+		}
+		return summary.TopErrors[i].Category < summary.TopErrors[j].Category
+	})
+	if len(summary.TopErrors) > maxSummaryErrorCategories {
+		summary.TopErrors = summary.TopErrors[:maxSummaryErrorCategories]
+	}
+
+	return summary
+}
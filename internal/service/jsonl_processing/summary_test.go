@@ -0,0 +1,105 @@
+package jsonl_processing
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mahesh-singh/review-system/internal/data"
+)
+
+func TestNewRunSummary(t *testing.T) {
+	errorsOf := func(categories ...string) []ProcessingError {
+		var errs []ProcessingError
+		for _, category := range categories {
+			errs = append(errs, ProcessingError{Category: category})
+		}
+		return errs
+	}
+
+	results := map[string]*ProcessingResult{
+		"s3://b/c.jl": {Status: data.FileStatusSuccess, Source: "booking", TotalRecords: 10, SuccessRecords: 10},
+		"s3://b/a.jl": {
+			Status: data.FileStatusPartial, Source: "booking", TotalRecords: 10, SuccessRecords: 7, ErrorRecords: 3,
+			Errors: errorsOf(ErrorCategoryJSONParse, ErrorCategoryJSONParse, ErrorCategoryHotel),
+		},
+		"s3://b/b.jl": {Status: data.FileStatusFailed, Source: "agoda", Errors: errorsOf(ErrorCategoryFile, "")},
+		"s3://b/d.jl": {Status: StatusSkipped},
+		"s3://b/e.jl": {Status: data.FileStatusInterrupted, Source: "agoda", TotalRecords: 4, SuccessRecords: 4},
+		"s3://b/f.jl": {Status: StatusNotStarted, Source: "agoda"},
+	}
+
+	startedAt := time.Now().Add(-time.Second)
+	summary := NewRunSummary(startedAt, results)
+
+	wantTotals := RunTotals{
+		TotalFiles: 6, SuccessFiles: 1, PartialFiles: 1, FailedFiles: 1, SkippedFiles: 1,
+		InterruptedFiles: 1, NotStartedFiles: 1, TotalRecords: 24, SuccessRecords: 21, ErrorRecords: 3,
+	}
+	if summary.RunTotals != wantTotals {
+		t.Errorf("totals = %+v, want %+v", summary.RunTotals, wantTotals)
+	}
+
+	wantSources := []SourceSummary{
+		{Name: "agoda", RunTotals: RunTotals{TotalFiles: 3, FailedFiles: 1, InterruptedFiles: 1, NotStartedFiles: 1, TotalRecords: 4, SuccessRecords: 4}},
+		{Name: "booking", RunTotals: RunTotals{TotalFiles: 2, SuccessFiles: 1, PartialFiles: 1, TotalRecords: 20, SuccessRecords: 17, ErrorRecords: 3}},
+	}
+	if !reflect.DeepEqual(summary.Sources, wantSources) {
+		t.Errorf("sources = %+v, want %+v", summary.Sources, wantSources)
+	}
+
+	var paths []string
+	for _, file := range summary.Files {
+		paths = append(paths, file.S3Path)
+	}
+	if want := []string{"s3://b/a.jl", "s3://b/b.jl", "s3://b/c.jl", "s3://b/d.jl", "s3://b/e.jl", "s3://b/f.jl"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("files = %v, want %v", paths, want)
+	}
+
+	// Most frequent first, ties by name, and errors without a category as other
+	wantErrors := []ErrorCategoryCount{
+		{ErrorCategoryJSONParse, 2}, {ErrorCategoryFile, 1}, {ErrorCategoryHotel, 1}, {ErrorCategoryOther, 1},
+	}
+	if !reflect.DeepEqual(summary.TopErrors, wantErrors) {
+		t.Errorf("top errors = %v, want %v", summary.TopErrors, wantErrors)
+	}
+
+	if summary.Outcome != "" {
+		t.Errorf("outcome = %q, want it left to the caller", summary.Outcome)
+	}
+	if !summary.StartedAt.Equal(startedAt) || summary.DurationMS < 1000 {
+		t.Errorf("started at %v after %dms, want %v after at least 1000ms", summary.StartedAt, summary.DurationMS, startedAt)
+	}
+}
+
+func TestNewRunSummaryLimitsErrorCategories(t *testing.T) {
+	var errs []ProcessingError
+	for i := range maxSummaryErrorCategories + 5 {
+		for range i + 1 {
+			errs = append(errs, ProcessingError{Category: fmt.Sprintf("category-%02d", i)})
+		}
+	}
+
+	summary := NewRunSummary(time.Now(), map[string]*ProcessingResult{
+		"s3://b/a.jl": {Status: data.FileStatusPartial, Errors: errs},
+	})
+
+	if len(summary.TopErrors) != maxSummaryErrorCategories {
+		t.Fatalf("%d error categories, want %d", len(summary.TopErrors), maxSummaryErrorCategories)
+	}
+	if top := summary.TopErrors[0]; top.Category != "category-14" || top.Count != 15 {
+		t.Errorf("top error = %+v, want category-14 with 15", top)
+	}
+}
+
+func TestNewRunSummaryEmpty(t *testing.T) {
+	summary := NewRunSummary(time.Now(), nil)
+
+	if summary.TopErrors == nil || summary.Sources == nil || summary.Files == nil {
+		t.Error("empty lists are nil, so the JSON summary would have nulls")
+	}
+	if summary.TotalFiles != 0 {
+		t.Errorf("total files = %d, want 0", summary.TotalFiles)
+	}
+}
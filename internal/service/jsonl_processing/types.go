@@ -1,7 +1,11 @@
 package jsonl_processing
 
 import (
+	"fmt"
+	"strings"
 	"time"
+
+	"github.com/mahesh-singh/review-system/internal/s3"
 )
 
 type ReviewerInfo struct {
@@ -74,8 +78,33 @@ type HotelReviewData struct {
 	OverallByProviders []OverallByProvider `json:"overallByProviders"`
 }
 
+const (
+	// StatusSkipped marks a file that was already processed in an earlier run
+	StatusSkipped = "Skipped"
+	// StatusNotStarted marks a queued file left untouched because the run was cancelled
+	StatusNotStarted = "NotStarted"
+)
+
+// Error categories used to group ProcessingErrors in run summaries
+const (
+	ErrorCategoryFile           = "file"
+	ErrorCategoryJSONParse      = "json_parse"
+	ErrorCategoryTransaction    = "transaction"
+	ErrorCategoryTimeout        = "timeout"
+	ErrorCategoryHotel          = "hotel"
+	ErrorCategoryProvider       = "provider"
+	ErrorCategoryCountry        = "country"
+	ErrorCategoryReviewGroup    = "review_group"
+	ErrorCategoryRoomType       = "room_type"
+	ErrorCategoryReview         = "review"
+	ErrorCategoryProviderRating = "provider_rating"
+	ErrorCategoryOther          = "other"
+)
+
 // ProcessingResult holds the results of processing a JSON file
 type ProcessingResult struct {
+	Status         string // Final processed_files status, or Skipped
+	Source         string
 	TotalRecords   int
 	SuccessRecords int
 	ErrorRecords   int
@@ -85,16 +114,63 @@ type ProcessingResult struct {
 
 type ProcessingError struct {
 	LineNumber int
+	Category   string
 	Error      string
 	RawData    string
 }
 
+// batchRecord is a parsed line waiting in the current batch
+type batchRecord struct {
+	LineNumber int
+	Data       *HotelReviewData
+}
+
 type FileToProcess struct {
 	Filename string
 	S3Path   string
+	Source   string        // Name of the ingestion source the file was listed from
+	Platform string        // Overrides HotelReviewData.Platform when set
+	Reader   s3.FileReader // Reader for the source's bucket
+	Force    bool          // Process even if an earlier run already succeeded
+}
+
+// FileError is the error of a single file in a multi-file run
+type FileError struct {
+	S3Path string
+	Err    error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.S3Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// MultiFileError aggregates the errors of every file that failed in a run
+type MultiFileError struct {
+	Errors []*FileError
+}
+
+func (e *MultiFileError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fileErr := range e.Errors {
+		messages = append(messages, fileErr.Error())
+	}
+	return fmt.Sprintf("%d file(s) failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *MultiFileError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, fileErr := range e.Errors {
+		errs = append(errs, fileErr)
+	}
+	return errs
 }
 
 type FileResult struct {
 	Filename string
 	Result   *ProcessingResult
+	Err      error
 }
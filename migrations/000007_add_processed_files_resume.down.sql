@@ -0,0 +1 @@
+ALTER TABLE processed_files DROP COLUMN IF EXISTS last_committed_line;
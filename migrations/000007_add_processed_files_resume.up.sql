@@ -0,0 +1 @@
+ALTER TABLE processed_files ADD COLUMN IF NOT EXISTS last_committed_line INTEGER NOT NULL DEFAULT 0;
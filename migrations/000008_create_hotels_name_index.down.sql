@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_hotels_name_prefix;
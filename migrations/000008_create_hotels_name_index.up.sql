@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_hotels_name_prefix ON hotels (lower(name) text_pattern_ops);
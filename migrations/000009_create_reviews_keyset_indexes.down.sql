@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reviews_hotel_rating_id;
+DROP INDEX IF EXISTS idx_reviews_hotel_review_date_id;
@@ -0,0 +1,3 @@
+-- Keyset pagination of a hotel's reviews by date or rating
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_review_date_id ON reviews (hotel_id, coalesce(review_date, '-infinity'::timestamp), id);
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_rating_id ON reviews (hotel_id, rating, id);
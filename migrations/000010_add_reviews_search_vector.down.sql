@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reviews_search_vector;
+ALTER TABLE reviews DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS review_ts_config(TEXT);
@@ -0,0 +1,32 @@
+-- Text search configuration for a review language code such as 'en' or 'pt-br'.
+-- Languages without a built-in configuration fall back to 'simple'.
+CREATE OR REPLACE FUNCTION review_ts_config(lang TEXT) RETURNS regconfig
+LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$
+    SELECT CASE lower(split_part(coalesce(lang, ''), '-', 1))
+        WHEN 'da' THEN 'danish'::regconfig
+        WHEN 'de' THEN 'german'::regconfig
+        WHEN 'en' THEN 'english'::regconfig
+        WHEN 'es' THEN 'spanish'::regconfig
+        WHEN 'fi' THEN 'finnish'::regconfig
+        WHEN 'fr' THEN 'french'::regconfig
+        WHEN 'hu' THEN 'hungarian'::regconfig
+        WHEN 'it' THEN 'italian'::regconfig
+        WHEN 'nl' THEN 'dutch'::regconfig
+        WHEN 'no' THEN 'norwegian'::regconfig
+        WHEN 'nb' THEN 'norwegian'::regconfig
+        WHEN 'pt' THEN 'portuguese'::regconfig
+        WHEN 'ro' THEN 'romanian'::regconfig
+        WHEN 'ru' THEN 'russian'::regconfig
+        WHEN 'sv' THEN 'swedish'::regconfig
+        WHEN 'tr' THEN 'turkish'::regconfig
+        ELSE 'simple'::regconfig
+    END
+$$;
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector(review_ts_config(translate_source), coalesce(review_title, '')), 'A') ||
+    setweight(to_tsvector(review_ts_config(translate_source), coalesce(review_comments, '')), 'B') ||
+    setweight(to_tsvector(review_ts_config(translate_source), coalesce(review_positives, '') || ' ' || coalesce(review_negatives, '')), 'C')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_search_vector ON reviews USING GIN (search_vector);
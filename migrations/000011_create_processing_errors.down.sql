@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_processed_files_processed_at;
+DROP TABLE IF EXISTS processing_errors;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS processing_errors (
+    id BIGSERIAL PRIMARY KEY,
+    processed_file_id INTEGER NOT NULL REFERENCES processed_files(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL DEFAULT 0,
+    category TEXT NOT NULL,
+    message TEXT NOT NULL,
+    raw_data TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_processing_errors_file_id ON processing_errors(processed_file_id, id);
+CREATE INDEX IF NOT EXISTS idx_processed_files_processed_at ON processed_files(processed_at);
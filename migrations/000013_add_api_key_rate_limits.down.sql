@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS api_key_usage;
+DROP TABLE IF EXISTS api_key_buckets;
+
+ALTER TABLE api_keys
+    DROP COLUMN IF EXISTS daily_quota,
+    DROP COLUMN IF EXISTS rate_limit_burst,
+    DROP COLUMN IF EXISTS rate_limit_rps;
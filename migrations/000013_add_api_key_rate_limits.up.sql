@@ -0,0 +1,20 @@
+-- NULL means the server-wide default applies
+ALTER TABLE api_keys
+    ADD COLUMN IF NOT EXISTS rate_limit_rps DOUBLE PRECISION CHECK (rate_limit_rps > 0),
+    ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER CHECK (rate_limit_burst > 0),
+    ADD COLUMN IF NOT EXISTS daily_quota INTEGER CHECK (daily_quota > 0);
+
+-- Shared limiter state for servers running with -limiter-shared
+CREATE TABLE IF NOT EXISTS api_key_buckets (
+    api_key_id BIGINT PRIMARY KEY REFERENCES api_keys(id) ON DELETE CASCADE,
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    refilled_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_key_usage (
+    api_key_id BIGINT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, day)
+);
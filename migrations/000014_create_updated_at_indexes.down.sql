@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_hotel_provider_ratings_hotel_updated_at;
+DROP INDEX IF EXISTS idx_reviews_hotel_updated_at;
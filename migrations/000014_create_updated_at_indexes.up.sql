@@ -0,0 +1,3 @@
+-- Let the API find the latest change to a hotel's data from the index alone
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_updated_at ON reviews (hotel_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_hotel_provider_ratings_hotel_updated_at ON hotel_provider_ratings (hotel_id, updated_at);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS hotel_daily_stats;
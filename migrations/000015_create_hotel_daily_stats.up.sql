@@ -0,0 +1,19 @@
+-- One row per hotel, provider and review day, kept up to date by the
+-- importer and rebuilt with `review-system rollup rebuild`
+CREATE TABLE IF NOT EXISTS hotel_daily_stats (
+    hotel_id BIGINT NOT NULL,
+    provider_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    review_count INTEGER NOT NULL,
+    rating_sum NUMERIC(12,1) NOT NULL,
+    -- Review counts by floor(rating), index 1 holds ratings below 1 and
+    -- index 11 ratings of 10 and above
+    rating_histogram INTEGER[] NOT NULL,
+    response_count INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (hotel_id, provider_id, day),
+    FOREIGN KEY (hotel_id) REFERENCES hotels(hotel_id) ON DELETE CASCADE,
+    FOREIGN KEY (provider_id) REFERENCES providers(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_hotel_daily_stats_hotel_day ON hotel_daily_stats (hotel_id, day);
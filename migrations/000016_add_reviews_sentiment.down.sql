@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reviews_hotel_sentiment;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS sentiment_version,
+    DROP COLUMN IF EXISTS sentiment_score;
@@ -0,0 +1,9 @@
+-- Lexicon sentiment of review_positives, review_negatives and review_comments,
+-- from -1 to 1. sentiment_version is the sentiment.Version that scored the row;
+-- NULL means not scored yet.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS sentiment_score NUMERIC(4,3) CHECK (sentiment_score BETWEEN -1 AND 1),
+    ADD COLUMN IF NOT EXISTS sentiment_version SMALLINT;
+
+-- Finding reviews whose text contradicts their rating
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_sentiment ON reviews (hotel_id, sentiment_score);
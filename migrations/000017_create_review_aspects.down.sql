@@ -0,0 +1,3 @@
+ALTER TABLE reviews DROP COLUMN IF EXISTS aspects_version;
+
+DROP TABLE IF EXISTS review_aspects;
@@ -0,0 +1,12 @@
+-- Hospitality aspects mentioned in review_positives (positive) and
+-- review_negatives (negative), with the number of matching terms
+CREATE TABLE IF NOT EXISTS review_aspects (
+    review_id BIGINT NOT NULL REFERENCES reviews(id) ON DELETE CASCADE,
+    aspect TEXT NOT NULL,
+    polarity TEXT NOT NULL CHECK (polarity IN ('positive', 'negative')),
+    mentions INTEGER NOT NULL CHECK (mentions > 0),
+    PRIMARY KEY (review_id, aspect, polarity)
+);
+
+-- Version of the aspect dictionary that tagged the review, NULL until tagged
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS aspects_version TEXT;
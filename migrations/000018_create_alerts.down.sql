@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS alerts;
+
+ALTER TABLE hotel_provider_ratings
+    DROP COLUMN IF EXISTS overall_score_changed_at,
+    DROP COLUMN IF EXISTS previous_overall_score;
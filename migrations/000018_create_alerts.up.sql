@@ -0,0 +1,24 @@
+-- The overall score a provider reported before its latest change, so a drop
+-- between imports can be detected
+ALTER TABLE hotel_provider_ratings
+    ADD COLUMN IF NOT EXISTS previous_overall_score DECIMAL(3,1),
+    ADD COLUMN IF NOT EXISTS overall_score_changed_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id BIGSERIAL PRIMARY KEY,
+    hotel_id BIGINT NOT NULL REFERENCES hotels(hotel_id) ON DELETE CASCADE,
+    provider_id INTEGER REFERENCES providers(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('rating_drop', 'overall_score_drop')),
+    severity TEXT NOT NULL CHECK (severity IN ('warning', 'critical')),
+    message TEXT NOT NULL,
+    baseline DOUBLE PRECISION NOT NULL,
+    observed DOUBLE PRECISION NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at TIMESTAMPTZ
+);
+
+-- At most one open alert of each kind per hotel and provider
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_open ON alerts (hotel_id, kind, (coalesce(provider_id, 0))) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_alerts_detected_at ON alerts (detected_at);
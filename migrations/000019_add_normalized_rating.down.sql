@@ -0,0 +1,42 @@
+-- The rollup goes back to raw rating sums and floor(rating) buckets, so the
+-- rows are recomputed from the reviews' raw ratings.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'hotel_daily_stats' AND column_name = 'normalized_rating_sum'
+    ) THEN
+        ALTER TABLE hotel_daily_stats RENAME COLUMN normalized_rating_sum TO rating_sum;
+    END IF;
+END
+$$;
+
+UPDATE hotel_daily_stats d
+SET rating_sum = f.rating_sum, rating_histogram = f.rating_histogram, updated_at = now()
+FROM (
+    SELECT hotel_id, provider_id, review_date::date AS day,
+        coalesce(sum(rating), 0) AS rating_sum,
+        ARRAY[
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 0),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 1),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 2),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 3),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 4),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 5),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 6),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 7),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 8),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 9),
+            count(*) FILTER (WHERE least(greatest(floor(rating)::int, 0), 10) = 10)
+        ]::integer[] AS rating_histogram
+    FROM reviews
+    WHERE review_date IS NOT NULL
+    GROUP BY hotel_id, provider_id, review_date::date
+) f
+WHERE d.hotel_id = f.hotel_id AND d.provider_id = f.provider_id AND d.day = f.day;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS normalized_rating;
+
+ALTER TABLE providers
+    DROP COLUMN IF EXISTS rating_scale;
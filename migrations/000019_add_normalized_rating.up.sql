@@ -0,0 +1,56 @@
+-- Maximum rating of each provider's scale, e.g. 10 or 5. reviews.rating and
+-- the provider grades are stored in this scale.
+ALTER TABLE providers
+    ADD COLUMN IF NOT EXISTS rating_scale NUMERIC(5,1) NOT NULL DEFAULT 10 CHECK (rating_scale > 0);
+
+-- rating / rating_scale on 0-100, so reviews from different providers can be
+-- averaged. NULL until set by the importer or `review-system ratings backfill`.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS normalized_rating NUMERIC(4,1) CHECK (normalized_rating BETWEEN 0 AND 100);
+
+-- Every provider starts on a scale of 10, so existing reviews are normalized
+-- here. Providers on another scale are corrected with `providers scale`
+-- followed by `ratings backfill`.
+UPDATE reviews r
+SET normalized_rating = least(round(r.rating / p.rating_scale * 100, 1), 100)
+FROM providers p
+WHERE p.id = r.provider_id AND r.rating IS NOT NULL AND r.normalized_rating IS NULL;
+
+-- The rollup sums and buckets normalized ratings; the histogram now counts
+-- by floor(normalized_rating / 10), so index 1 holds 0-9.9 and index 11
+-- holds 100. The existing rows hold raw sums and buckets, so they are
+-- recomputed from the reviews normalized above.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'hotel_daily_stats' AND column_name = 'rating_sum'
+    ) THEN
+        ALTER TABLE hotel_daily_stats RENAME COLUMN rating_sum TO normalized_rating_sum;
+    END IF;
+END
+$$;
+
+UPDATE hotel_daily_stats d
+SET normalized_rating_sum = f.normalized_rating_sum, rating_histogram = f.rating_histogram, updated_at = now()
+FROM (
+    SELECT hotel_id, provider_id, review_date::date AS day,
+        coalesce(sum(normalized_rating), 0) AS normalized_rating_sum,
+        ARRAY[
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 0),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 1),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 2),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 3),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 4),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 5),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 6),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 7),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 8),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 9),
+            count(*) FILTER (WHERE floor(normalized_rating / 10)::int = 10)
+        ]::integer[] AS rating_histogram
+    FROM reviews
+    WHERE review_date IS NOT NULL
+    GROUP BY hotel_id, provider_id, review_date::date
+) f
+WHERE d.hotel_id = f.hotel_id AND d.provider_id = f.provider_id AND d.day = f.day;
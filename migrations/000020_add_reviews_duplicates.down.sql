@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_reviews_cluster;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS cluster_id,
+    DROP COLUMN IF EXISTS simhash_version,
+    DROP COLUMN IF EXISTS text_simhash;
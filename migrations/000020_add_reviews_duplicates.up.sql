@@ -0,0 +1,12 @@
+-- SimHash of the normalised review text, NULL for texts too short to sign.
+-- simhash_version is the dedupe.Version that signed the row; NULL means not
+-- signed yet.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS text_simhash BIGINT,
+    ADD COLUMN IF NOT EXISTS simhash_version SMALLINT,
+    -- Smallest review id of the near-duplicate cluster the review belongs
+    -- to, its own id when it has no duplicates and NULL until clustered.
+    -- Analytics count a cluster once, through the review whose id it is.
+    ADD COLUMN IF NOT EXISTS cluster_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_cluster ON reviews (cluster_id) WHERE cluster_id IS NOT NULL;
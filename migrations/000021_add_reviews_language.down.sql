@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_reviews_hotel_language;
+
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS language_version,
+    DROP COLUMN IF EXISTS language_confidence,
+    DROP COLUMN IF EXISTS language;
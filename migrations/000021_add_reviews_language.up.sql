@@ -0,0 +1,9 @@
+-- ISO 639-1 code of the language a review was written in, detected offline
+-- from its text. NULL when the text is too short to tell. language_version
+-- is the langid.Version that looked at the row; NULL means not detected yet.
+ALTER TABLE reviews
+    ADD COLUMN IF NOT EXISTS language TEXT,
+    ADD COLUMN IF NOT EXISTS language_confidence NUMERIC(4,3) CHECK (language_confidence BETWEEN 0 AND 1),
+    ADD COLUMN IF NOT EXISTS language_version SMALLINT;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_language ON reviews (hotel_id, language);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_reviews_room_type;
+
+ALTER TABLE reviews DROP COLUMN IF EXISTS room_type_id;
+
+DROP TABLE IF EXISTS room_types;
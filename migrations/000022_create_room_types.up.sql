@@ -0,0 +1,22 @@
+-- Room types of a hotel as each provider names them. provider_room_type_id
+-- is the id the feed sends, 0 when it has none. category is the canonical
+-- room type the name maps to, see package roomtypes, and rules_version the
+-- version of the rules that mapped it.
+CREATE TABLE IF NOT EXISTS room_types (
+    id SERIAL PRIMARY KEY,
+    hotel_id BIGINT NOT NULL REFERENCES hotels(hotel_id) ON DELETE CASCADE,
+    provider_id INTEGER NOT NULL REFERENCES providers(id) ON DELETE RESTRICT,
+    provider_room_type_id INTEGER NOT NULL DEFAULT 0,
+    name VARCHAR(200) NOT NULL,
+    category VARCHAR(100) NOT NULL,
+    rules_version TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (hotel_id, provider_id, provider_room_type_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_types_hotel_category ON room_types (hotel_id, category);
+
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS room_type_id INTEGER REFERENCES room_types(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_reviews_room_type ON reviews (room_type_id) WHERE room_type_id IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_room_types_provider_room_type;
+
+DROP TABLE IF EXISTS lookup_name_changes;
+DROP TABLE IF EXISTS review_group_external_ids;
+DROP TABLE IF EXISTS country_external_ids;
+DROP TABLE IF EXISTS provider_external_ids;
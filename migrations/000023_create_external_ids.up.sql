@@ -0,0 +1,89 @@
+-- Ids the feed of each platform uses for providers, countries and review
+-- groups, mapped onto the lookup rows. name is the name the platform last
+-- sent with the id.
+CREATE TABLE IF NOT EXISTS provider_external_ids (
+    platform VARCHAR(100) NOT NULL,
+    external_id INTEGER NOT NULL,
+    provider_id INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform, external_id)
+);
+
+CREATE TABLE IF NOT EXISTS country_external_ids (
+    platform VARCHAR(100) NOT NULL,
+    external_id INTEGER NOT NULL,
+    country_id INTEGER NOT NULL REFERENCES countries(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform, external_id)
+);
+
+CREATE TABLE IF NOT EXISTS review_group_external_ids (
+    platform VARCHAR(100) NOT NULL,
+    external_id INTEGER NOT NULL,
+    review_group_id INTEGER NOT NULL REFERENCES review_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (platform, external_id)
+);
+
+-- Names a platform changed for an external id it had sent before
+CREATE TABLE IF NOT EXISTS lookup_name_changes (
+    id BIGSERIAL PRIMARY KEY,
+    lookup TEXT NOT NULL CHECK (lookup IN ('provider', 'country', 'review_group', 'room_type')),
+    lookup_id INTEGER NOT NULL,
+    platform VARCHAR(100) NOT NULL,
+    external_id INTEGER NOT NULL,
+    old_name VARCHAR(200) NOT NULL,
+    new_name VARCHAR(200) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_lookup_name_changes_lookup ON lookup_name_changes (lookup, lookup_id);
+
+-- Room types with the same provider room type id are one room type the
+-- provider renamed. Keep the most recently updated row of each, recording
+-- the names of the others as renames so they still resolve to it.
+INSERT INTO lookup_name_changes (lookup, lookup_id, platform, external_id, old_name, new_name, changed_at)
+SELECT 'room_type', keep.id, h.platform, old.provider_room_type_id, old.name, keep.name,
+    coalesce(keep.updated_at, CURRENT_TIMESTAMP)
+FROM (
+    SELECT id, first_value(id) OVER (
+        PARTITION BY hotel_id, provider_id, provider_room_type_id ORDER BY updated_at DESC, id DESC
+    ) AS keep_id
+    FROM room_types
+    WHERE provider_room_type_id <> 0
+) m
+JOIN room_types old ON old.id = m.id
+JOIN room_types keep ON keep.id = m.keep_id
+JOIN hotels h ON h.hotel_id = old.hotel_id
+WHERE m.id <> m.keep_id AND old.name <> keep.name;
+
+UPDATE reviews r
+SET room_type_id = m.keep_id
+FROM (
+    SELECT id, first_value(id) OVER (
+        PARTITION BY hotel_id, provider_id, provider_room_type_id ORDER BY updated_at DESC, id DESC
+    ) AS keep_id
+    FROM room_types
+    WHERE provider_room_type_id <> 0
+) m
+WHERE r.room_type_id = m.id AND m.id <> m.keep_id;
+
+DELETE FROM room_types rt
+USING (
+    SELECT id, first_value(id) OVER (
+        PARTITION BY hotel_id, provider_id, provider_room_type_id ORDER BY updated_at DESC, id DESC
+    ) AS keep_id
+    FROM room_types
+    WHERE provider_room_type_id <> 0
+) m
+WHERE rt.id = m.id AND m.id <> m.keep_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_types_provider_room_type
+    ON room_types (hotel_id, provider_id, provider_room_type_id)
+    WHERE provider_room_type_id <> 0;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_rating_id ON reviews (hotel_id, rating, id);
+DROP INDEX IF EXISTS idx_reviews_hotel_normalized_rating_id;
@@ -0,0 +1,4 @@
+-- Keyset pagination of a hotel's reviews by normalized rating, which the
+-- rating sort uses since ratings are on per-provider scales
+CREATE INDEX IF NOT EXISTS idx_reviews_hotel_normalized_rating_id ON reviews (hotel_id, coalesce(normalized_rating, -1), id);
+DROP INDEX IF EXISTS idx_reviews_hotel_rating_id;
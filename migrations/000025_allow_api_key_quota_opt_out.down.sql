@@ -0,0 +1,4 @@
+-- Keys without a quota go back to the server default
+UPDATE api_keys SET daily_quota = NULL WHERE daily_quota = 0;
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS api_keys_daily_quota_check;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_daily_quota_check CHECK (daily_quota > 0);
@@ -0,0 +1,3 @@
+-- A daily_quota of 0 exempts a key from the server's default quota
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS api_keys_daily_quota_check;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_daily_quota_check CHECK (daily_quota >= 0);